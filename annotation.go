@@ -10,27 +10,27 @@ import (
 )
 
 type annotation struct {
-	Table  string
-	Option string
+	Table         string
+	Schema        string
+	Option        string
+	IgnoreColumns []string
+	Unmanaged     bool
+
+	// Comment is the table comment. If it's nil, the doc comment is used instead.
+	Comment *string
 }
 
 func parseAnnotation(g *ast.CommentGroup) (*annotation, error) {
 	for _, c := range g.List {
-		if !strings.HasPrefix(c.Text, commentPrefix) {
-			continue
-		}
-		s := strings.TrimSpace(c.Text[len(commentPrefix):])
-		if !strings.HasPrefix(s, marker) {
+		s, ok := annotationText(c)
+		if !ok {
 			continue
 		}
-		if len(s) == len(marker) {
+		if s == "" {
 			return &annotation{}, nil
 		}
-		if !isSpace(s[len(marker)]) {
-			continue
-		}
 		var a annotation
-		scanner := bufio.NewScanner(strings.NewReader(s[len(marker):]))
+		scanner := bufio.NewScanner(strings.NewReader(s))
 		scanner.Split(splitAnnotationTags)
 		for scanner.Scan() {
 			ss := strings.SplitN(scanner.Text(), string(annotationSeparator), 2)
@@ -41,12 +41,44 @@ func parseAnnotation(g *ast.CommentGroup) (*annotation, error) {
 					return nil, fmt.Errorf("migu: BUG: %v", err)
 				}
 				a.Table = s
+			case "schema":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %v", err)
+				}
+				a.Schema = s
 			case "option":
 				s, err := parseString(v)
 				if err != nil {
 					return nil, fmt.Errorf("migu: BUG: %v", err)
 				}
 				a.Option = s
+			case "ignore_columns":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %v", err)
+				}
+				for _, column := range strings.Split(s, ",") {
+					if column = strings.TrimSpace(column); column != "" {
+						a.IgnoreColumns = append(a.IgnoreColumns, column)
+					}
+				}
+			case "managed":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %v", err)
+				}
+				managed, err := strconv.ParseBool(s)
+				if err != nil {
+					return nil, fmt.Errorf("migu: invalid value of managed annotation: %v", s)
+				}
+				a.Unmanaged = !managed
+			case "comment":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %v", err)
+				}
+				a.Comment = &s
 			default:
 				return nil, fmt.Errorf("migu: unsupported annotation: %v", k)
 			}
@@ -59,6 +91,32 @@ func parseAnnotation(g *ast.CommentGroup) (*annotation, error) {
 	return nil, nil
 }
 
+// annotationText returns the text after the marker if c is the annotation comment.
+func annotationText(c *ast.Comment) (string, bool) {
+	if !strings.HasPrefix(c.Text, commentPrefix) {
+		return "", false
+	}
+	s := strings.TrimSpace(c.Text[len(commentPrefix):])
+	if !strings.HasPrefix(s, marker) {
+		return "", false
+	}
+	if len(s) > len(marker) && !isSpace(s[len(marker)]) {
+		return "", false
+	}
+	return s[len(marker):], true
+}
+
+// docComment returns the text of the doc comment except the annotation.
+func docComment(g *ast.CommentGroup) string {
+	var list []*ast.Comment
+	for _, c := range g.List {
+		if _, ok := annotationText(c); !ok {
+			list = append(list, c)
+		}
+	}
+	return strings.TrimSpace((&ast.CommentGroup{List: list}).Text())
+}
+
 func splitAnnotationTags(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	if atEOF {
 		return 0, nil, nil
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	apply := &apply{}
+	applyCmd := &cobra.Command{
+		Use:   "apply [OPTIONS] DATABASE PLANFILE",
+		Short: "apply the plan saved by the plan command",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return apply.Execute(args, option)
+		},
+	}
+	applyCmd.Flags().BoolVarP(&apply.Quiet, "quiet", "q", false, "")
+	applyCmd.SetUsageTemplate(usageTemplate + "\nThe plan will not be applied if the database schema has been changed since the plan was made.\n")
+	rootCmd.AddCommand(applyCmd)
+}
+
+type apply struct {
+	Quiet bool
+}
+
+func (a *apply) Execute(args []string, opt *Option) error {
+	args, err := withEnvironmentArgs(args, opt, 2, false)
+	if err != nil {
+		return err
+	}
+	var dbname string
+	var filename string
+	switch len(args) {
+	case 0, 1:
+		return fmt.Errorf("too few arguments")
+	case 2:
+		dbname, filename = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	f, err := migu.ReadPlanFile(filename)
+	if err != nil {
+		return err
+	}
+	di, closeDB, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return a.run(ctx, di, opt, f)
+}
+
+func (a *apply) run(ctx context.Context, d dialect.Dialect, opt *Option, f *migu.PlanFile) error {
+	fingerprint, err := migu.Fingerprint(ctx, d, f.Plan)
+	if err != nil {
+		return err
+	}
+	if fingerprint != f.Fingerprint {
+		return fmt.Errorf("the database schema has been changed since the plan was made. Please make the plan again")
+	}
+	if err := checkPolicy(f.Plan, opt); err != nil {
+		return err
+	}
+	return applyPlan(ctx, d, f.Plan, a.printf)
+}
+
+func (a *apply) printf(format string, args ...interface{}) (int, error) {
+	if a.Quiet {
+		return 0, nil
+	}
+	return fmt.Printf(format, args...)
+}
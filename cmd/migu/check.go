@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkExitCodeChanged = 1
+	checkExitCodeError   = 2
+)
+
+func init() {
+	check := &check{}
+	checkCmd := &cobra.Command{
+		Use:   "check [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "check whether the database schema is synchronized",
+		Annotations: map[string]string{
+			annotationErrorExitCode: strconv.Itoa(checkExitCodeError),
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			err := check.Execute(args, option)
+			var e *exitError
+			if errors.As(err, &e) {
+				cmd.SilenceErrors = true
+			}
+			return err
+		},
+	}
+	checkCmd.Flags().StringSliceVar(&check.IgnoreTables, "ignore-tables", nil, "Comma-separated list of the tables to ignore")
+	checkCmd.Flags().StringVar(&check.Output, "output", outputText, "The output format (text|json|yaml|sql)")
+	checkCmd.Flags().BoolVarP(&check.Quiet, "quiet", "q", false, "")
+	checkCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n" +
+		"The exit status is 0 if the database schema is synchronized, 1 if some changes are needed, and 2 if an error occurred.\n")
+	rootCmd.AddCommand(checkCmd)
+}
+
+type check struct {
+	IgnoreTables []string
+	Output       string
+	Quiet        bool
+}
+
+func (c *check) Execute(args []string, opt *Option) error {
+	args, err := withEnvironmentArgs(args, opt, 2, true)
+	if err != nil {
+		return err
+	}
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if err := validateOutput(c.Output); err != nil {
+		return err
+	}
+	di, closeDB, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return c.run(ctx, di, opt, file)
+}
+
+func (c *check) run(ctx context.Context, d dialect.Dialect, opt *Option, file string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		src = b
+	}
+	plan, err := migu.MakePlan(ctx, d, file, src)
+	if err != nil {
+		return err
+	}
+	plan = filterIgnoredTables(plan, opt)
+	plan = plan.ExcludeTables(c.IgnoreTables...)
+	if c.Output == outputText {
+		printPlan(plan, c.printf)
+	} else if !c.Quiet {
+		if err := newReport(plan).write(os.Stdout, c.Output); err != nil {
+			return err
+		}
+	}
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+	return &exitError{code: checkExitCodeChanged}
+}
+
+func (c *check) printf(format string, a ...interface{}) (int, error) {
+	if c.Quiet {
+		return 0, nil
+	}
+	return fmt.Printf(format, a...)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/goccy/go-yaml"
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/pflag"
+)
+
+const (
+	configFileName = "migu.yaml"
+
+	// defaultEnvironment is the environment that is used if --env is not specified.
+	defaultEnvironment = "default"
+)
+
+// config is the configuration file.
+type config struct {
+	Environments map[string]*environment `yaml:"environments"`
+}
+
+// environment is the settings of the environment in the configuration file.
+// The settings are used unless the corresponding flags are specified.
+type environment struct {
+	Type     string `yaml:"type"`
+	Database string `yaml:"database"`
+
+	// Schema is the path to the schema source, which is relative to the configuration file.
+	Schema string `yaml:"schema"`
+
+	// MySQL/MariaDB
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Protocol string `yaml:"protocol"`
+	SSLMode  string `yaml:"ssl_mode"`
+	SSLCA    string `yaml:"ssl_ca"`
+	SSLCert  string `yaml:"ssl_cert"`
+	SSLKey   string `yaml:"ssl_key"`
+
+	// Cloud Spanner
+	Project         string `yaml:"project"`
+	Instance        string `yaml:"instance"`
+	DDLBatchSize    int    `yaml:"ddl_batch_size"`
+	Endpoint        string `yaml:"spanner_endpoint"`
+	CredentialsFile string `yaml:"credentials_file"`
+	DialTimeout     string `yaml:"dial_timeout"`
+
+	ColumnTypes    []*dialect.ColumnType `yaml:"column_types"`
+	ColumnTypeFile string                `yaml:"column_type_file"`
+
+	// IgnoreTables is the list of the tables that are never changed.
+	IgnoreTables []string `yaml:"ignore_tables"`
+
+	// Tables and ExcludeTables are the same as --tables and --exclude-tables.
+	Tables        []string `yaml:"tables"`
+	ExcludeTables []string `yaml:"exclude_tables"`
+
+	// IgnoreColumns is the same as --ignore-columns.
+	IgnoreColumns []string `yaml:"ignore_columns"`
+
+	Policy *policy `yaml:"policy"`
+}
+
+// policy is the safety policy of the environment.
+type policy struct {
+	// Deny is the list of the kinds of changes that must not be applied.
+	Deny []migu.ChangeKind `yaml:"deny"`
+}
+
+// findConfigFile returns the path to the configuration file that is found
+// by searching upward from dir. It returns an empty string if not found.
+func findConfigFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		filename := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(filename); err == nil {
+			return filename, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func readConfigFile(filename string) (*config, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var c config
+	if err := yaml.UnmarshalWithOptions(b, &c, yaml.DisallowUnknownField(), yaml.DisallowDuplicateKey()); err != nil {
+		return nil, fmt.Errorf("failed to decode config file %s: %w", filename, err)
+	}
+	return &c, nil
+}
+
+// loadConfig applies the environment of the configuration file to opt.
+// The settings which are specified by flags take precedence over the environment.
+func loadConfig(flags *pflag.FlagSet, opt *Option) error {
+	filename := opt.global.Config
+	if filename == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if filename, err = findConfigFile(wd); err != nil {
+			return err
+		}
+		if filename == "" {
+			if opt.global.Env != "" {
+				return fmt.Errorf("environment %q is specified, but %s is not found", opt.global.Env, configFileName)
+			}
+			return nil
+		}
+	}
+	c, err := readConfigFile(filename)
+	if err != nil {
+		return err
+	}
+	name := opt.global.Env
+	if name == "" {
+		name = defaultEnvironment
+	}
+	env := c.Environments[name]
+	if env == nil {
+		if opt.global.Env == "" {
+			return nil
+		}
+		return fmt.Errorf("environment %q is not defined in %s", name, filename)
+	}
+	opt.global.Env = name
+	return env.apply(flags, opt, filepath.Dir(filename))
+}
+
+func (env *environment) apply(flags *pflag.FlagSet, opt *Option, dir string) error {
+	set := func(name, value string) error {
+		if value == "" || flags.Changed(name) {
+			return nil
+		}
+		return flags.Set(name, value)
+	}
+	setInt := func(name string, value int) error {
+		if value == 0 {
+			return nil
+		}
+		return set(name, strconv.Itoa(value))
+	}
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	for _, err := range []error{
+		set("type", env.Type),
+		set("host", env.Host),
+		setInt("port", env.Port),
+		set("user", env.User),
+		set("password", env.Password),
+		set("protocol", env.Protocol),
+		set("ssl-mode", env.SSLMode),
+		set("ssl-ca", resolve(env.SSLCA)),
+		set("ssl-cert", resolve(env.SSLCert)),
+		set("ssl-key", resolve(env.SSLKey)),
+		set("project", env.Project),
+		set("instance", env.Instance),
+		setInt("ddl-batch-size", env.DDLBatchSize),
+		set("spanner-endpoint", env.Endpoint),
+		set("credentials-file", resolve(env.CredentialsFile)),
+		set("dial-timeout", env.DialTimeout),
+		set("column-type-file", resolve(env.ColumnTypeFile)),
+	} {
+		if err != nil {
+			return fmt.Errorf("invalid environment: %w", err)
+		}
+	}
+	if len(env.Tables) > 0 && !flags.Changed("tables") {
+		opt.global.Tables = env.Tables
+	}
+	if len(env.ExcludeTables) > 0 && !flags.Changed("exclude-tables") {
+		opt.global.ExcludeTables = env.ExcludeTables
+	}
+	if len(env.IgnoreColumns) > 0 && !flags.Changed("ignore-columns") {
+		opt.global.IgnoreColumns = env.IgnoreColumns
+	}
+	if len(env.ColumnTypes) > 0 && opt.global.columnTypeFile == "" {
+		opt.global.ColumnTypes = env.ColumnTypes
+	}
+	if opt.global.Database == "" {
+		opt.global.Database = env.Database
+	}
+	opt.global.Schema = resolve(env.Schema)
+	opt.global.IgnoreTables = env.IgnoreTables
+	if env.Policy != nil {
+		opt.global.Deny = env.Policy.Deny
+	}
+	return nil
+}
+
+// withEnvironmentArgs returns args that are completed by the database and the schema source of the environment.
+// maxArgs is the maximum number of the arguments of the command including the database.
+// If the database is given by the DSN or the environment, it's filled only if args omit it,
+// i.e. args have no arguments, or the first argument is an existing file or - and args have room for the database.
+// Otherwise, unless the first argument is the same database, the database is
+// given by both and an error is returned.
+// The schema source is completed only if withSchema is true.
+func withEnvironmentArgs(args []string, opt *Option, maxArgs int, withSchema bool) ([]string, error) {
+	if db := opt.global.Database; db != "" && (len(args) == 0 || args[0] != db) {
+		if len(args) > 0 && (len(args) >= maxArgs || !isFileArg(args[0])) {
+			return nil, fmt.Errorf("the database is given by both the argument %q and the DSN or the environment %q", args[0], db)
+		}
+		args = append([]string{db}, args...)
+	}
+	if len(args) == 1 && withSchema && opt.global.Schema != "" {
+		args = append(args, opt.global.Schema)
+	}
+	return args, nil
+}
+
+// isFileArg reports whether the argument is an existing file or directory, or - for standard input/output.
+func isFileArg(arg string) bool {
+	if arg == "-" {
+		return true
+	}
+	_, err := os.Stat(arg)
+	return err == nil
+}
+
+// filterIgnoredTables returns the plan without the changes of the tables that are ignored by the environment.
+func filterIgnoredTables(plan *migu.Plan, opt *Option) *migu.Plan {
+	if len(opt.global.IgnoreTables) == 0 {
+		return plan
+	}
+	return plan.ExcludeTables(opt.global.IgnoreTables...)
+}
+
+// checkPolicy returns an error if the plan has the changes that are denied by the safety policy of the environment.
+func checkPolicy(plan *migu.Plan, opt *Option) error {
+	for _, change := range plan.Changes {
+		for _, kind := range opt.global.Deny {
+			if change.Kind == kind {
+				return fmt.Errorf("%s of table `%s' is denied by the policy of environment %q", kind, change.Table, opt.global.Env)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu"
+	"github.com/spf13/pflag"
+)
+
+func TestWithEnvironmentArgs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	schema := filepath.Join(dir, "schema.go")
+	if err := ioutil.WriteFile(schema, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []struct {
+		name       string
+		database   string
+		schema     string
+		args       []string
+		maxArgs    int
+		withSchema bool
+		expect     []string
+		err        bool
+	}{
+		{"no environment", "", "", []string{"migu"}, 2, true, []string{"migu"}, false},
+		{"database omitted", "prod", "", nil, 2, true, []string{"prod"}, false},
+		{"same database", "prod", "", []string{"prod", schema}, 2, true, []string{"prod", schema}, false},
+		{"file without database", "prod", "", []string{schema}, 2, true, []string{"prod", schema}, false},
+		{"stdin without database", "prod", "", []string{"-"}, 2, true, []string{"prod", "-"}, false},
+		{"schema of environment", "prod", schema, nil, 2, true, []string{"prod", schema}, false},
+		{"schema of environment is not used", "prod", schema, nil, 2, false, []string{"prod"}, false},
+		{"given schema takes precedence", "", "other.go", []string{"migu", schema}, 2, true, []string{"migu", schema}, false},
+		{"different database", "prod", "", []string{"otherdb"}, 2, true, nil, true},
+		{"different database with file", "prod", "", []string{"otherdb", schema}, 2, true, nil, true},
+		{"no room for database", "prod", "", []string{schema}, 1, false, nil, true},
+	} {
+		v := v
+		t.Run(v.name, func(t *testing.T) {
+			opt := &Option{}
+			opt.global.Database = v.database
+			opt.global.Schema = v.schema
+			actual, err := withEnvironmentArgs(v.args, opt, v.maxArgs, v.withSchema)
+			if v.err {
+				if err == nil {
+					t.Errorf("withEnvironmentArgs(%q, ...) => %q; want error", v.args, actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(actual, v.expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		})
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	plan := &migu.Plan{
+		Changes: []*migu.Change{
+			{Kind: migu.AddColumn, Table: "user"},
+			{Kind: migu.DropColumn, Table: "user"},
+		},
+	}
+	for _, v := range []struct {
+		deny []migu.ChangeKind
+		err  bool
+	}{
+		{nil, false},
+		{[]migu.ChangeKind{migu.DropTable}, false},
+		{[]migu.ChangeKind{migu.DropTable, migu.DropColumn}, true},
+	} {
+		opt := &Option{}
+		opt.global.Env = "production"
+		opt.global.Deny = v.deny
+		if err := checkPolicy(plan, opt); (err != nil) != v.err {
+			t.Errorf("checkPolicy(..., %q) => %v; want error %v", v.deny, err, v.err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := strings.Join([]string{
+		"environments:",
+		"  default:",
+		"    type: mariadb",
+		"    database: migu_development",
+		"    host: localhost",
+		"    schema: schema.go",
+		"  production:",
+		"    database: migu_production",
+		"    host: db.example.com",
+		"    policy:",
+		"      deny: [drop_table]",
+	}, "\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, configFileName), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subdir := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	emptyDir := filepath.Join(dir, "..", filepath.Base(dir)+"-empty")
+	if err := os.MkdirAll(emptyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(emptyDir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	t.Run("findConfigFile", func(t *testing.T) {
+		actual, err := findConfigFile(subdir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expect := filepath.Join(dir, configFileName); actual != expect {
+			t.Errorf("findConfigFile(%q) => %q; want %q", subdir, actual, expect)
+		}
+	})
+
+	type result struct {
+		Type, Database, Schema, Host, Env string
+		Deny                              []migu.ChangeKind
+	}
+	for _, v := range []struct {
+		name   string
+		wd     string
+		args   []string
+		env    string
+		expect result
+		err    bool
+	}{
+		{"default environment in subdirectory", subdir, nil, "", result{
+			Type: "mariadb", Database: "migu_development", Schema: filepath.Join(dir, "schema.go"), Host: "localhost", Env: "default",
+		}, false},
+		{"named environment", subdir, nil, "production", result{
+			Type: "mysql", Database: "migu_production", Host: "db.example.com", Env: "production", Deny: []migu.ChangeKind{migu.DropTable},
+		}, false},
+		{"flag takes precedence", dir, []string{"--host", "127.0.0.1"}, "", result{
+			Type: "mariadb", Database: "migu_development", Schema: filepath.Join(dir, "schema.go"), Host: "127.0.0.1", Env: "default",
+		}, false},
+		{"undefined environment", dir, nil, "staging", result{}, true},
+		{"no config file", emptyDir, nil, "", result{Type: "mysql"}, false},
+		{"environment without config file", emptyDir, nil, "production", result{}, true},
+	} {
+		v := v
+		t.Run(v.name, func(t *testing.T) {
+			if err := os.Chdir(v.wd); err != nil {
+				t.Fatal(err)
+			}
+			opt := &Option{}
+			opt.global.Env = v.env
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringVar(&opt.global.DatabaseType, "type", databaseTypeMySQL, "")
+			flags.StringVar(&opt.mysql.Host, "host", "", "")
+			if err := flags.Parse(v.args); err != nil {
+				t.Fatal(err)
+			}
+			err := loadConfig(flags, opt)
+			if v.err {
+				if err == nil {
+					t.Errorf("loadConfig(...) => nil; want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := result{
+				Type:     opt.global.DatabaseType,
+				Database: opt.global.Database,
+				Schema:   opt.global.Schema,
+				Host:     opt.mysql.Host,
+				Env:      opt.global.Env,
+				Deny:     opt.global.Deny,
+			}
+			if diff := cmp.Diff(actual, v.expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	diff := &diff{}
+	diffCmd := &cobra.Command{
+		Use:   "diff [OPTIONS] {--from DATABASE_A --to DATABASE_B | --from-source SOURCE_A --to-source SOURCE_B}",
+		Short: "output the difference between the schemas of two databases or two Go sources",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diff.Execute(args, option)
+		},
+	}
+	diffCmd.Flags().StringVar(&diff.From, "from", "", "The database name or DSN of the database to be migrated")
+	diffCmd.Flags().StringVar(&diff.To, "to", "", "The database name or DSN of the database that has the desired schema")
+	diffCmd.Flags().StringVar(&diff.FromSource, "from-source", "", "The FILE or DIRECTORY of Go's structs to be migrated")
+	diffCmd.Flags().StringVar(&diff.ToSource, "to-source", "", "The FILE or DIRECTORY of Go's structs that has the desired schema")
+	diffCmd.Flags().StringVar(&diff.Output, "output", outputSQL, "The output format (text|json|yaml|sql)")
+	diffCmd.SetUsageTemplate(usageTemplate + "\nThe output is the SQLs to migrate the schema of DATABASE_A (SOURCE_A) to the one of DATABASE_B (SOURCE_B).\n" +
+		"DATABASE can be the DSN to connect to the database on the different server from the other (e.g. mysql://user@host:3306/db).\n" +
+		"The connection settings which are not contained in the DSN are given by the options.\n" +
+		"SOURCE can be git:REF:PATH to read the FILE or DIRECTORY at PATH from the root of the Git repository at REF.\n" +
+		"The sources are compared without connecting to any database.\n")
+	rootCmd.AddCommand(diffCmd)
+}
+
+type diff struct {
+	From       string
+	To         string
+	FromSource string
+	ToSource   string
+	Output     string
+}
+
+func (d *diff) Execute(args []string, opt *Option) error {
+	if len(args) > 0 {
+		return fmt.Errorf("too many arguments")
+	}
+	if err := validateOutput(d.Output); err != nil {
+		return err
+	}
+	switch {
+	case d.FromSource != "" || d.ToSource != "":
+		if d.From != "" || d.To != "" {
+			return fmt.Errorf("--from-source and --to-source cannot be used with --from and --to")
+		}
+		if d.FromSource == "" || d.ToSource == "" {
+			return fmt.Errorf("both --from-source and --to-source must be specified")
+		}
+		di, err := newOfflineDialect(opt)
+		if err != nil {
+			return err
+		}
+		return d.runSource(di)
+	case d.From == "" || d.To == "":
+		return fmt.Errorf("both --from and --to must be specified")
+	}
+	fromOpt, fromDB, err := databaseOption(d.From, opt)
+	if err != nil {
+		return fmt.Errorf("--from: %w", err)
+	}
+	toOpt, toDB, err := databaseOption(d.To, opt)
+	if err != nil {
+		return fmt.Errorf("--to: %w", err)
+	}
+	if fromOpt.global.DatabaseType != toOpt.global.DatabaseType {
+		return fmt.Errorf("--from and --to must be the same database type: %s and %s", fromOpt.global.DatabaseType, toOpt.global.DatabaseType)
+	}
+	from, closeFrom, err := newDialect(fromDB, fromOpt)
+	if err != nil {
+		return err
+	}
+	defer closeFrom()
+	to, closeTo, err := newDialect(toDB, toOpt)
+	if err != nil {
+		return err
+	}
+	defer closeTo()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return d.run(ctx, from, to)
+}
+
+// databaseOption returns the option and the database name to connect to the database given by --from or --to.
+// If the database is given by the DSN, its connection settings override the ones of opt.
+func databaseOption(database string, opt *Option) (*Option, string, error) {
+	if !isDSN(database) {
+		return opt, database, nil
+	}
+	o, err := withDSN(opt, database)
+	if err != nil {
+		return nil, "", err
+	}
+	if o.global.Database == "" {
+		return nil, "", fmt.Errorf("database is not specified in the DSN")
+	}
+	return o, o.global.Database, nil
+}
+
+func (d *diff) run(ctx context.Context, from, to dialect.Dialect) error {
+	plan, err := migu.MakeDatabasePlan(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	return d.print(plan)
+}
+
+func (d *diff) runSource(di dialect.Dialect) error {
+	from, cleanupFrom, err := readSource(d.FromSource)
+	if err != nil {
+		return err
+	}
+	defer cleanupFrom()
+	to, cleanupTo, err := readSource(d.ToSource)
+	if err != nil {
+		return err
+	}
+	defer cleanupTo()
+	plan, err := migu.MakeSourcePlan(di, from, nil, to, nil)
+	if err != nil {
+		return err
+	}
+	return d.print(plan)
+}
+
+func (d *diff) print(plan *migu.Plan) error {
+	if d.Output == outputText {
+		printPlan(plan, fmt.Printf)
+		return nil
+	}
+	return newReport(plan).write(os.Stdout, d.Output)
+}
+
+// readSource returns the path of the source.
+// If the source is in the form of git:REF:PATH, the file or directory at REF
+// is extracted into the temporary directory. The returned function must be
+// called to remove it after use.
+func readSource(source string) (string, func(), error) {
+	if !strings.HasPrefix(source, "git:") {
+		return source, func() {}, nil
+	}
+	s := strings.SplitN(strings.TrimPrefix(source, "git:"), ":", 2)
+	if len(s) != 2 || s[0] == "" {
+		return "", nil, fmt.Errorf("invalid source: %s: must be in the form of git:REF:PATH", source)
+	}
+	ref, p := s[0], path.Clean(s[1])
+	if p == "." {
+		p = ""
+	}
+	dir, err := ioutil.TempDir("", progName)
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	filename, err := extractGitSource(dir, ref, p)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return filename, cleanup, nil
+}
+
+func extractGitSource(dir, ref, p string) (string, error) {
+	typ, err := git("cat-file", "-t", ref+":"+p)
+	if err != nil {
+		return "", err
+	}
+	switch typ := strings.TrimSpace(string(typ)); typ {
+	case "blob":
+		filename := filepath.Join(dir, path.Base(p))
+		if err := extractGitFile(filename, ref, p); err != nil {
+			return "", err
+		}
+		return filename, nil
+	case "tree":
+		out, err := git("ls-tree", "--name-only", ref+":"+p)
+		if err != nil {
+			return "", err
+		}
+		for _, name := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if !strings.HasSuffix(name, ".go") {
+				continue
+			}
+			if err := extractGitFile(filepath.Join(dir, name), ref, path.Join(p, name)); err != nil {
+				return "", err
+			}
+		}
+		return dir, nil
+	default:
+		return "", fmt.Errorf("%s:%s is not a file or directory: %s", ref, p, typ)
+	}
+}
+
+func extractGitFile(filename, ref, p string) error {
+	b, err := git("show", ref+":"+p)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
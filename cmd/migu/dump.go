@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
-	"path"
 
+	"github.com/goccy/go-yaml"
 	"github.com/naoina/migu"
 	"github.com/naoina/migu/dialect"
 	"github.com/spf13/cobra"
@@ -14,18 +16,32 @@ func init() {
 	dump := &dump{}
 	dumpCmd := &cobra.Command{
 		Use:   "dump [OPTIONS] DATABASE [FILE]",
-		Short: "dump the database schema as Go code",
+		Short: "dump the database schema as Go code or SQL",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return dump.Execute(args, option)
 		},
 	}
+	dumpCmd.Flags().StringVar(&dump.Format, "format", dumpFormatGo, "The output format (go|sql|yaml|json)")
 	dumpCmd.SetUsageTemplate(usageTemplate + "\nWith FILE, output to FILE.\n")
 	rootCmd.AddCommand(dumpCmd)
 }
 
-type dump struct{}
+const (
+	dumpFormatGo   = "go"
+	dumpFormatSQL  = "sql"
+	dumpFormatYAML = "yaml"
+	dumpFormatJSON = "json"
+)
+
+type dump struct {
+	Format string
+}
 
 func (d *dump) Execute(args []string, opt *Option) error {
+	args, err := withEnvironmentArgs(args, opt, 2, false)
+	if err != nil {
+		return err
+	}
 	var dbname string
 	var filename string
 	switch len(args) {
@@ -38,28 +54,22 @@ func (d *dump) Execute(args []string, opt *Option) error {
 	default:
 		return fmt.Errorf("too many arguments")
 	}
-	var opts []dialect.Option
-	if columnTypes := opt.global.ColumnTypes; len(columnTypes) != 0 {
-		opts = append(opts, dialect.WithColumnType(columnTypes))
-	}
-	var di dialect.Dialect
-	switch typ := opt.global.DatabaseType; typ {
-	case databaseTypeMySQL, databaseTypeMariaDB:
-		db, err := openDatabase(dbname)
-		if err != nil {
-			return err
-		}
-		defer db.Close()
-		di = dialect.NewMySQL(db, opts...)
-	case databaseTypeSpanner:
-		di = dialect.NewSpanner(path.Join("projects", opt.spanner.Project, "instances", opt.spanner.Instance, "databases", dbname), opts...)
+	switch d.Format {
+	case dumpFormatGo, dumpFormatSQL, dumpFormatYAML, dumpFormatJSON:
 	default:
-		return fmt.Errorf("BUG: unknown database type: %s", typ)
+		return fmt.Errorf("unknown format: %s", d.Format)
+	}
+	di, closeDB, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
 	}
-	return d.run(di, filename)
+	defer closeDB()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return d.run(ctx, di, filename)
 }
 
-func (d *dump) run(di dialect.Dialect, filename string) error {
+func (d *dump) run(ctx context.Context, di dialect.Dialect, filename string) error {
 	out := os.Stdout
 	if filename != "" {
 		file, err := os.Create(filename)
@@ -69,5 +79,26 @@ func (d *dump) run(di dialect.Dialect, filename string) error {
 		defer file.Close()
 		out = file
 	}
-	return migu.Fprint(out, di)
+	switch d.Format {
+	case dumpFormatSQL:
+		return migu.FprintSQLContext(ctx, out, di)
+	case dumpFormatYAML, dumpFormatJSON:
+		schema, err := migu.DumpSchema(ctx, di)
+		if err != nil {
+			return err
+		}
+		var b []byte
+		if d.Format == dumpFormatYAML {
+			b, err = yaml.Marshal(schema)
+		} else {
+			b, err = json.MarshalIndent(schema, "", "  ")
+			b = append(b, '\n')
+		}
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(b)
+		return err
+	}
+	return migu.FprintContext(ctx, out, di)
 }
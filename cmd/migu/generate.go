@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+const (
+	migrationFormatGolangMigrate = "golang-migrate"
+	migrationFormatGoose         = "goose"
+	migrationFormatAtlas         = "atlas"
+	migrationFormatSQL           = "sql"
+)
+
+func init() {
+	generate := &generate{}
+	generateCmd := &cobra.Command{
+		Use:   "generate [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "generate the versioned migration files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generate.Execute(args, option)
+		},
+	}
+	generateCmd.Flags().StringVar(&generate.Format, "format", migrationFormatSQL, "The format of migration files (golang-migrate|goose|atlas|sql)")
+	generateCmd.Flags().StringVar(&generate.Dir, "dir", "migrations", "The directory to write migration files")
+	generateCmd.Flags().StringVar(&generate.Name, "name", "migu", "The name of the migration")
+	generateCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n" +
+		"The down migration is written only if all changes can be reverted.\n" +
+		"The statements in the down migration that cannot restore the data are marked with the warning comment.\n")
+	rootCmd.AddCommand(generateCmd)
+}
+
+type generate struct {
+	Format string
+	Dir    string
+	Name   string
+}
+
+func (g *generate) Execute(args []string, opt *Option) error {
+	args, err := withEnvironmentArgs(args, opt, 2, true)
+	if err != nil {
+		return err
+	}
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	switch g.Format {
+	case migrationFormatGolangMigrate, migrationFormatGoose, migrationFormatAtlas, migrationFormatSQL:
+		// do nothing.
+	default:
+		return fmt.Errorf("unknown migration format: %s", g.Format)
+	}
+	di, closeDB, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return g.run(ctx, di, opt, file)
+}
+
+func (g *generate) run(ctx context.Context, d dialect.Dialect, opt *Option, file string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		src = b
+	}
+	plan, err := migu.MakePlan(ctx, d, file, src)
+	if err != nil {
+		return err
+	}
+	plan = filterIgnoredTables(plan, opt)
+	if len(plan.Changes) == 0 {
+		fmt.Println("no changes")
+		return nil
+	}
+	up := joinSQLs(plan.SQLs())
+	var down string
+	reversed, reverseErr := migu.Reverse(plan)
+	reversible := reverseErr == nil
+	if reversible {
+		down = downSQL(reversed)
+	}
+	if err := os.MkdirAll(g.Dir, 0755); err != nil {
+		return err
+	}
+	prefix := time.Now().UTC().Format("20060102150405") + "_" + g.Name
+	var files []migrationFile
+	switch g.Format {
+	case migrationFormatGolangMigrate:
+		files = append(files, migrationFile{prefix + ".up.sql", up})
+		if reversible {
+			files = append(files, migrationFile{prefix + ".down.sql", down})
+		}
+	case migrationFormatGoose:
+		content := "-- +goose Up\n" + up
+		if reversible {
+			content += "\n-- +goose Down\n" + down
+		}
+		files = append(files, migrationFile{prefix + ".sql", content})
+	case migrationFormatAtlas:
+		// Atlas doesn't have the down migration.
+		files = append(files, migrationFile{prefix + ".sql", up})
+	case migrationFormatSQL:
+		files = append(files, migrationFile{prefix + ".sql", up})
+		if reversible {
+			files = append(files, migrationFile{prefix + ".down.sql", down})
+		}
+	}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(g.Dir, f.name)); err == nil {
+			return fmt.Errorf("%s already exists", filepath.Join(g.Dir, f.name))
+		}
+	}
+	for _, f := range files {
+		filename := filepath.Join(g.Dir, f.name)
+		if err := ioutil.WriteFile(filename, []byte(f.content), 0644); err != nil {
+			return err
+		}
+		fmt.Println(filename)
+	}
+	switch {
+	case g.Format == migrationFormatAtlas:
+		fmt.Fprintln(os.Stderr, "run `atlas migrate hash` to update atlas.sum")
+	case !reversible:
+		fmt.Fprintf(os.Stderr, "the down migration has not been written: %v\n", reverseErr)
+	}
+	return nil
+}
+
+type migrationFile struct {
+	name    string
+	content string
+}
+
+// downSQL returns the SQLs of the reversed plan with the warning of irreversible changes.
+func downSQL(plan *migu.Plan) string {
+	var b strings.Builder
+	for _, c := range plan.Changes {
+		if c.Irreversible {
+			fmt.Fprintf(&b, "-- WARNING: %s of %s cannot restore the data.\n", c.Kind, c.Table)
+		}
+		b.WriteString(joinSQLs(c.SQLs))
+	}
+	return b.String()
+}
+
+func joinSQLs(sqls []string) string {
+	var b strings.Builder
+	for _, sql := range sqls {
+		fmt.Fprintf(&b, "%s;\n", strings.TrimRight(sql, ";"))
+	}
+	return b.String()
+}
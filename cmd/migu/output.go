@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/naoina/migu"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+	outputSQL  = "sql"
+)
+
+func validateOutput(format string) error {
+	switch format {
+	case outputText, outputJSON, outputYAML, outputSQL:
+		return nil
+	}
+	return fmt.Errorf("unknown output format: %s", format)
+}
+
+type changeResult struct {
+	Kind     migu.ChangeKind `json:"kind" yaml:"kind"`
+	Table    string          `json:"table" yaml:"table"`
+	Column   string          `json:"column,omitempty" yaml:"column,omitempty"`
+	Index    string          `json:"index,omitempty" yaml:"index,omitempty"`
+	SQL      []string        `json:"sqls" yaml:"sqls"`
+	Duration *float64        `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Error    string          `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// report collects the results of the changes to output them in the machine-readable format.
+type report struct {
+	Changes []*changeResult `json:"changes" yaml:"changes"`
+
+	resultMap map[*migu.Change]*changeResult
+}
+
+func newReport(plan *migu.Plan) *report {
+	r := &report{
+		Changes:   make([]*changeResult, 0, len(plan.Changes)),
+		resultMap: make(map[*migu.Change]*changeResult, len(plan.Changes)),
+	}
+	for _, c := range plan.Changes {
+		result := &changeResult{
+			Kind:   c.Kind,
+			Table:  c.Table,
+			Column: c.Column,
+			Index:  c.Index,
+			SQL:    c.SQLs,
+		}
+		r.Changes = append(r.Changes, result)
+		r.resultMap[c] = result
+	}
+	return r
+}
+
+// hook records the duration and the error of each statement. It can be passed to migu.Apply.
+func (r *report) hook(c *migu.Change, sql string) func(error) {
+	result := r.resultMap[c]
+	start := time.Now()
+	return func(err error) {
+		if result == nil {
+			return
+		}
+		d := time.Since(start).Seconds()
+		if result.Duration != nil {
+			d += *result.Duration
+		}
+		result.Duration = &d
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+}
+
+func (r *report) write(w io.Writer, format string) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case outputYAML:
+		b, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case outputSQL:
+		for _, c := range r.Changes {
+			if _, err := io.WriteString(w, joinSQLs(c.SQL)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("BUG: unknown output format: %s", format)
+}
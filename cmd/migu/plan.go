@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	plan := &plan{}
+	planCmd := &cobra.Command{
+		Use:   "plan [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "save the plan of synchronization to apply it later",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return plan.Execute(args, option)
+		},
+	}
+	planCmd.Flags().StringVarP(&plan.Output, "output", "o", "", "Write the plan to the file instead of standard output")
+	planCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n" +
+		"The saved plan can be applied by the apply command.\n")
+	rootCmd.AddCommand(planCmd)
+}
+
+type plan struct {
+	Output string
+}
+
+func (p *plan) Execute(args []string, opt *Option) error {
+	args, err := withEnvironmentArgs(args, opt, 2, true)
+	if err != nil {
+		return err
+	}
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	di, closeDB, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return p.run(ctx, di, opt, file)
+}
+
+func (p *plan) run(ctx context.Context, d dialect.Dialect, opt *Option, file string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		src = b
+	}
+	plan, err := migu.MakePlan(ctx, d, file, src)
+	if err != nil {
+		return err
+	}
+	plan = filterIgnoredTables(plan, opt)
+	fingerprint, err := migu.Fingerprint(ctx, d, plan)
+	if err != nil {
+		return err
+	}
+	f := &migu.PlanFile{
+		Fingerprint: fingerprint,
+		Plan:        plan,
+	}
+	if p.Output != "" {
+		return f.WriteFile(p.Output)
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Printf("%s\n", b)
+	return err
+}
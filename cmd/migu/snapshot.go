@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	snapshot := &snapshot{}
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot [OPTIONS] DATABASE",
+		Short: "save the snapshot of the database schema",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return snapshot.Execute(args, option)
+		},
+	}
+	snapshotCmd.Flags().StringVarP(&snapshot.Output, "output", "o", "", "Write the snapshot to the file instead of standard output.\nThe format is YAML if the extension is .yaml or .yml, otherwise JSON")
+	snapshotCmd.Flags().StringVar(&snapshot.Format, "format", outputJSON, "The format of standard output (json|yaml)")
+	snapshotCmd.SetUsageTemplate(usageTemplate + "\nThe snapshot can be used instead of the database by --snapshot option.\n")
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+type snapshot struct {
+	Output string
+	Format string
+}
+
+func (s *snapshot) Execute(args []string, opt *Option) error {
+	args, err := withEnvironmentArgs(args, opt, 1, false)
+	if err != nil {
+		return err
+	}
+	var dbname string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	switch s.Format {
+	case outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unknown format: %s", s.Format)
+	}
+	di, closeDB, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return s.run(ctx, di)
+}
+
+func (s *snapshot) run(ctx context.Context, d dialect.Dialect) error {
+	schemas, err := d.ColumnSchemaContext(ctx)
+	if err != nil {
+		return err
+	}
+	var tableSchemas []dialect.TableSchema
+	if r, ok := d.(dialect.TableSchemaReader); ok {
+		if tableSchemas, err = r.TableSchemaContext(ctx); err != nil {
+			return err
+		}
+	}
+	snapshot := dialect.NewSnapshot(schemas, tableSchemas...)
+	if r, ok := d.(dialect.DefaultCollationReader); ok {
+		if snapshot.DefaultCollations, err = r.DefaultCollationsContext(ctx); err != nil {
+			return err
+		}
+	}
+	if s.Output != "" {
+		return snapshot.WriteFile(s.Output)
+	}
+	var b []byte
+	switch s.Format {
+	case outputYAML:
+		b, err = yaml.Marshal(snapshot)
+	default:
+		b, err = json.MarshalIndent(snapshot, "", "  ")
+		b = append(b, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Printf("%s", b)
+	return err
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
-	"path"
 	"time"
 
 	"github.com/naoina/migu"
@@ -26,16 +28,28 @@ func init() {
 	}
 	syncCmd.Flags().BoolVar(&sync.DryRun, "dry-run", false, "")
 	syncCmd.Flags().BoolVarP(&sync.Quiet, "quiet", "q", false, "")
-	syncCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	syncCmd.Flags().StringVar(&sync.Output, "output", outputText, "The output format (text|json|yaml|sql)")
+	syncCmd.Flags().BoolVar(&sync.Resume, "resume", false, "Resume the previous sync that has failed in the middle")
+	syncCmd.Flags().StringVar(&sync.StateFile, "state-file", ".migu-state.json", "The file to record the progress of sync for --resume")
+	syncCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n" +
+		"If FILE has the .sql extension, the schema is read from CREATE TABLE and CREATE INDEX statements.\n" +
+		"If FILE has the .yaml, .yml or .json extension, the schema is read from the declarative schema.\n")
 	rootCmd.AddCommand(syncCmd)
 }
 
 type sync struct {
-	DryRun bool
-	Quiet  bool
+	DryRun    bool
+	Quiet     bool
+	Output    string
+	Resume    bool
+	StateFile string
 }
 
 func (s *sync) Execute(args []string, opt *Option) error {
+	args, err := withEnvironmentArgs(args, opt, 2, true)
+	if err != nil {
+		return err
+	}
 	var dbname string
 	var file string
 	switch len(args) {
@@ -48,65 +62,106 @@ func (s *sync) Execute(args []string, opt *Option) error {
 	default:
 		return fmt.Errorf("too many arguments")
 	}
-	var opts []dialect.Option
-	if columnTypes := opt.global.ColumnTypes; len(columnTypes) != 0 {
-		opts = append(opts, dialect.WithColumnType(columnTypes))
-	}
-	var di dialect.Dialect
-	switch typ := opt.global.DatabaseType; typ {
-	case databaseTypeMySQL, databaseTypeMariaDB:
-		db, err := openDatabase(dbname)
-		if err != nil {
-			return err
-		}
-		defer db.Close()
-		di = dialect.NewMySQL(db, opts...)
-	case databaseTypeSpanner:
-		di = dialect.NewSpanner(path.Join("projects", opt.spanner.Project, "instances", opt.spanner.Instance, "databases", dbname), opts...)
-	default:
-		return fmt.Errorf("BUG: unknown database type: %s", typ)
+	if err := validateOutput(s.Output); err != nil {
+		return err
 	}
-	if !s.DryRun {
-		dryRunMarker = ""
+	di, closeDB, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
 	}
-	return s.run(di, file)
+	defer closeDB()
+	ctx, cancel := newContext(opt)
+	defer cancel()
+	return s.run(ctx, di, opt, dbname, file)
 }
 
-func (s *sync) run(d dialect.Dialect, file string) error {
+func (s *sync) run(ctx context.Context, d dialect.Dialect, opt *Option, dbname, file string) error {
 	var src interface{}
 	switch file {
 	case "", "-":
 		file = ""
-		src = os.Stdin
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		src = b
 	}
-	sqls, err := migu.Diff(d, file, src)
+	hash, err := migu.SourceHash(file, src)
 	if err != nil {
 		return err
 	}
-	var tx dialect.Transactioner
+	var state *migu.State
+	if s.Resume {
+		if state, err = migu.ReadStateFile(s.StateFile); err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		if state.Database != dbname {
+			return fmt.Errorf("cannot resume: the state file is for the database %q", state.Database)
+		}
+		if state.SourceHash != hash {
+			return fmt.Errorf("cannot resume: the schema source has been changed since the plan was made")
+		}
+	} else {
+		// The dry run is allowed to see the plan from scratch while the state file exists.
+		if _, err := os.Stat(s.StateFile); err == nil && !s.DryRun {
+			return fmt.Errorf("the previous sync has not been completed. Run with --resume to continue it, or remove %s to discard it", s.StateFile)
+		}
+		plan, err := migu.MakePlan(ctx, d, file, src)
+		if err != nil {
+			return err
+		}
+		plan = filterIgnoredTables(plan, opt)
+		state = &migu.State{
+			Database:   dbname,
+			SourceHash: hash,
+			Plan:       plan,
+		}
+	}
+	plan := state.Remaining()
 	if !s.DryRun {
-		if tx, err = d.Begin(); err != nil {
+		if err := checkPolicy(plan, opt); err != nil {
 			return err
 		}
 	}
-	for _, sql := range sqls {
-		s.printf("--------%sapplying--------\n", dryRunMarker)
-		s.printf("%s\n", sql)
-		start := time.Now()
-		if !s.DryRun {
-			if err := tx.Exec(sql); err != nil {
-				tx.Rollback()
-				return err
-			}
+	if err := s.apply(ctx, d, plan); err != nil {
+		var aerr *migu.ApplyError
+		if errors.As(err, &aerr) {
+			state.Applied += len(aerr.Applied)
+		}
+		if state.Applied == 0 {
+			return err
+		}
+		if werr := state.WriteFile(s.StateFile); werr != nil {
+			return fmt.Errorf("%w\nfailed to record the progress: %v", err, werr)
 		}
-		d := time.Since(start)
-		s.printf("--------%sdone %.3fs--------\n", dryRunMarker, d.Seconds()/time.Second.Seconds())
+		return fmt.Errorf("%w\nthe progress has been recorded to %s. Run with --resume to continue", err, s.StateFile)
 	}
-	if s.DryRun {
-		return nil
-	} else {
-		return tx.Commit()
+	// The state file is kept on the dry run because nothing has been applied.
+	if s.Resume && !s.DryRun {
+		return os.Remove(s.StateFile)
 	}
+	return nil
+}
+
+func (s *sync) apply(ctx context.Context, d dialect.Dialect, plan *migu.Plan) error {
+	if s.Output == outputText {
+		if s.DryRun {
+			printPlan(plan, s.printf)
+			return nil
+		}
+		return applyPlan(ctx, d, plan, s.printf)
+	}
+	r := newReport(plan)
+	var err error
+	if !s.DryRun {
+		err = migu.Apply(ctx, d, plan, r.hook)
+	}
+	if !s.Quiet {
+		if werr := r.write(os.Stdout, s.Output); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
 }
 
 func (s *sync) printf(format string, a ...interface{}) (int, error) {
@@ -115,3 +170,28 @@ func (s *sync) printf(format string, a ...interface{}) (int, error) {
 	}
 	return fmt.Printf(format, a...)
 }
+
+// printPlan prints the SQLs of the plan without applying them.
+func printPlan(plan *migu.Plan, printf func(format string, a ...interface{}) (int, error)) {
+	for _, sql := range plan.SQLs() {
+		printf("--------%sapplying--------\n", dryRunMarker)
+		printf("%s\n", sql)
+		printf("--------%sdone %.3fs--------\n", dryRunMarker, 0.0)
+	}
+}
+
+// applyPlan applies the plan to the database with printing the progress.
+func applyPlan(ctx context.Context, d dialect.Dialect, plan *migu.Plan, printf func(format string, a ...interface{}) (int, error)) error {
+	return migu.Apply(ctx, d, plan, func(c *migu.Change, sql string) func(error) {
+		printf("--------applying--------\n")
+		printf("%s\n", sql)
+		start := time.Now()
+		return func(err error) {
+			if err != nil {
+				return
+			}
+			d := time.Since(start)
+			printf("--------done %.3fs--------\n", d.Seconds()/time.Second.Seconds())
+		}
+	})
+}
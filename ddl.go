@@ -0,0 +1,826 @@
+package migu
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// isDDLFile reports whether the file is the SQL file of DDL statements.
+func isDDLFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".sql")
+}
+
+// makeDDLTableMap returns the tables which are defined by CREATE TABLE and
+// CREATE INDEX statements of MySQL or Cloud Spanner.
+// The filename and src parameters are the same as Sync.
+func makeDDLTableMap(d dialect.Dialect, filename string, src interface{}) (map[string]*table, error) {
+	b, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	p := &ddlParser{filename: filename}
+	if err := p.parse(b); err != nil {
+		return nil, err
+	}
+	tableMap := make(map[string]*table, len(p.snapshot.Tables))
+	for _, t := range p.snapshot.Tables {
+		fields, err := makeFields(d, t.Name, p.snapshot.ColumnSchema(t.Name))
+		if err != nil {
+			return nil, err
+		}
+		tbl := &table{
+			Name:   t.Name,
+			Fields: fields,
+			Option: t.Option,
+		}
+		if t.Comment != nil {
+			tbl.Comment = *t.Comment
+		}
+		tableMap[t.Name] = tbl
+	}
+	return tableMap, nil
+}
+
+func readSource(filename string, src interface{}) ([]byte, error) {
+	switch s := src.(type) {
+	case nil:
+		return ioutil.ReadFile(filename)
+	case string:
+		return []byte(s), nil
+	case []byte:
+		return s, nil
+	case io.Reader:
+		return ioutil.ReadAll(s)
+	default:
+		return nil, fmt.Errorf("migu: unsupported type of source: %T", src)
+	}
+}
+
+type ddlTokenKind int
+
+const (
+	ddlIdent ddlTokenKind = iota
+	ddlQuotedIdent
+	ddlString
+	ddlSymbol
+	ddlEOF
+)
+
+type ddlToken struct {
+	kind  ddlTokenKind
+	value string
+	line  int
+
+	// The offsets of the token in the source.
+	start int
+	end   int
+}
+
+// is reports whether the token is the given keyword or symbol.
+func (t ddlToken) is(s string) bool {
+	return (t.kind == ddlIdent || t.kind == ddlSymbol) && strings.EqualFold(t.value, s)
+}
+
+func (t ddlToken) String() string {
+	switch t.kind {
+	case ddlEOF:
+		return "EOF"
+	case ddlString:
+		return fmt.Sprintf("'%s'", t.value)
+	case ddlQuotedIdent:
+		return fmt.Sprintf("`%s`", t.value)
+	}
+	return t.value
+}
+
+func tokenizeDDL(src []byte) ([]ddlToken, error) {
+	var tokens []ddlToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case isSpace(c) || c == '\r':
+			i++
+		case c == '#' || (c == '-' && bytes.HasPrefix(src[i:], []byte("--"))):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && bytes.HasPrefix(src[i:], []byte("/*")):
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			end += i + 4
+			line += bytes.Count(src[i:end], []byte("\n"))
+			i = end
+		case c == '`' || c == '\'' || c == '"':
+			start, startLine := i, line
+			var buf []byte
+			for i++; ; i++ {
+				if i >= len(src) {
+					return nil, fmt.Errorf("line %d: unterminated quoted string", startLine)
+				}
+				if src[i] == '\n' {
+					line++
+				}
+				if src[i] == '\\' && c != '`' && i+1 < len(src) {
+					i++
+					buf = append(buf, unescapeDDL(src[i]))
+					continue
+				}
+				if src[i] == c {
+					if i+1 < len(src) && src[i+1] == c {
+						i++
+					} else {
+						break
+					}
+				}
+				buf = append(buf, src[i])
+			}
+			i++
+			kind := ddlString
+			if c == '`' {
+				kind = ddlQuotedIdent
+			}
+			tokens = append(tokens, ddlToken{kind: kind, value: string(buf), line: startLine, start: start, end: i})
+		case isDDLIdentChar(c):
+			start := i
+			for i < len(src) && isDDLIdentChar(src[i]) {
+				i++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlIdent, value: string(src[start:i]), line: line, start: start, end: i})
+		default:
+			tokens = append(tokens, ddlToken{kind: ddlSymbol, value: string(c), line: line, start: i, end: i + 1})
+			i++
+		}
+	}
+	return append(tokens, ddlToken{kind: ddlEOF, line: line, start: len(src), end: len(src)}), nil
+}
+
+func isDDLIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c == '.' ||
+		('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c >= 0x80
+}
+
+func unescapeDDL(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 0x1a
+	}
+	return c
+}
+
+// ddlParser parses CREATE TABLE and CREATE INDEX statements into the snapshot.
+type ddlParser struct {
+	filename string
+	src      []byte
+	tokens   []ddlToken
+	pos      int
+	snapshot dialect.Snapshot
+}
+
+func (p *ddlParser) parse(src []byte) error {
+	tokens, err := tokenizeDDL(src)
+	if err != nil {
+		return p.wrapError(err)
+	}
+	p.src, p.tokens = src, tokens
+	for {
+		for p.accept(";") {
+		}
+		if p.peek().kind == ddlEOF {
+			return nil
+		}
+		if err := p.parseStatement(); err != nil {
+			return p.wrapError(err)
+		}
+	}
+}
+
+func (p *ddlParser) wrapError(err error) error {
+	if p.filename == "" {
+		return fmt.Errorf("migu: %w", err)
+	}
+	return fmt.Errorf("migu: %s: %w", p.filename, err)
+}
+
+func (p *ddlParser) peek() ddlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.tokens[p.pos]
+	if t.kind != ddlEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next tokens if they are the given keywords or symbols.
+func (p *ddlParser) accept(words ...string) bool {
+	for i, w := range words {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].is(w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *ddlParser) expect(words ...string) error {
+	if !p.accept(words...) {
+		return p.unexpected(strings.Join(words, " "))
+	}
+	return nil
+}
+
+func (p *ddlParser) unexpected(expected string) error {
+	t := p.peek()
+	return fmt.Errorf("line %d: unexpected %v, expected %s", t.line, t, expected)
+}
+
+func (p *ddlParser) ident() (string, error) {
+	switch t := p.peek(); t.kind {
+	case ddlIdent, ddlQuotedIdent:
+		p.pos++
+		return t.value, nil
+	}
+	return "", p.unexpected("identifier")
+}
+
+// tableName parses the table name that may be qualified by the schema as SCHEMA.TABLE.
+// The index name of Cloud Spanner can also be qualified in the same way.
+func (p *ddlParser) tableName() (string, error) {
+	name, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+	if t := p.peek(); t.kind == ddlIdent && strings.HasPrefix(t.value, ".") {
+		p.pos++
+		name += t.value
+	}
+	if strings.HasSuffix(name, ".") {
+		table, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		name += table
+	}
+	return name, nil
+}
+
+// skipParens skips the tokens enclosed in the parentheses and returns the source text of them.
+func (p *ddlParser) skipParens() (string, error) {
+	start := p.peek()
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	for depth := 1; depth > 0; {
+		t := p.next()
+		switch {
+		case t.kind == ddlEOF:
+			return "", fmt.Errorf("line %d: unclosed parenthesis", start.line)
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		}
+	}
+	return string(p.src[start.start:p.tokens[p.pos-1].end]), nil
+}
+
+// skipStatement skips the tokens until the end of the statement and returns the source text of them.
+func (p *ddlParser) skipStatement() string {
+	start := p.peek()
+	for t := p.peek(); t.kind != ddlEOF && !t.is(";"); t = p.peek() {
+		if t.is("(") {
+			if _, err := p.skipParens(); err != nil {
+				break
+			}
+			continue
+		}
+		p.pos++
+	}
+	return strings.TrimSpace(string(p.src[start.start:p.peek().start]))
+}
+
+func (p *ddlParser) parseStatement() error {
+	t := p.peek()
+	if !p.accept("CREATE") {
+		return fmt.Errorf("line %d: unsupported statement: %v", t.line, t)
+	}
+	switch {
+	case p.accept("TABLE"):
+		return p.parseCreateTable()
+	case p.accept("INDEX"):
+		return p.parseCreateIndex(false)
+	case p.accept("UNIQUE"):
+		p.accept("NULL_FILTERED")
+		if err := p.expect("INDEX"); err != nil {
+			return err
+		}
+		return p.parseCreateIndex(true)
+	case p.accept("NULL_FILTERED"):
+		if err := p.expect("INDEX"); err != nil {
+			return err
+		}
+		return p.parseCreateIndex(false)
+	}
+	return fmt.Errorf("line %d: unsupported statement: CREATE %v", t.line, p.peek())
+}
+
+func (p *ddlParser) table(name string) *dialect.SnapshotTable {
+	for _, t := range p.snapshot.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (p *ddlParser) parseCreateTable() error {
+	p.accept("IF", "NOT", "EXISTS")
+	line := p.peek().line
+	name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if p.table(name) != nil {
+		return fmt.Errorf("line %d: table `%s' is already defined", line, name)
+	}
+	t := &dialect.SnapshotTable{Name: name}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := p.parseTableElement(t); err != nil {
+			return err
+		}
+		if p.accept(")") {
+			break
+		}
+		if err := p.expect(","); err != nil {
+			return err
+		}
+		// Cloud Spanner allows the trailing comma.
+		if p.accept(")") {
+			break
+		}
+	}
+	// PRIMARY KEY of Cloud Spanner is specified after the column definitions.
+	if p.accept("PRIMARY", "KEY") {
+		columns, err := p.parseIndexColumns()
+		if err != nil {
+			return err
+		}
+		if err := p.setPrimaryKey(t, columns, false); err != nil {
+			return err
+		}
+		p.accept(",")
+	}
+	option, err := p.parseTableOptions(t)
+	if err != nil {
+		return err
+	}
+	t.Option = option
+	p.snapshot.Tables = append(p.snapshot.Tables, t)
+	return nil
+}
+
+// parseTableOptions parses the table options until the end of the statement.
+// The table comment is set to t, and the source text of the other options is returned.
+func (p *ddlParser) parseTableOptions(t *dialect.SnapshotTable) (string, error) {
+	var options []string
+	start := p.peek().start
+	for tok := p.peek(); tok.kind != ddlEOF && !tok.is(";"); tok = p.peek() {
+		switch {
+		case tok.is("("):
+			if _, err := p.skipParens(); err != nil {
+				return "", err
+			}
+		case p.accept("COMMENT"):
+			options = append(options, string(p.src[start:tok.start]))
+			p.accept("=")
+			s := p.next()
+			if s.kind != ddlString {
+				p.pos--
+				return "", p.unexpected("string")
+			}
+			if s.value != "" {
+				t.Comment = &s.value
+			}
+			start = p.peek().start
+		default:
+			p.pos++
+		}
+	}
+	options = append(options, string(p.src[start:p.peek().start]))
+	var parts []string
+	for _, option := range options {
+		// The comma is the optional separator of the table options.
+		if option = strings.Trim(option, " \t\r\n,"); option != "" {
+			parts = append(parts, option)
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func (p *ddlParser) parseTableElement(t *dialect.SnapshotTable) error {
+	start := p.peek()
+	if p.accept("CONSTRAINT") {
+		if !p.peek().is("PRIMARY") && !p.peek().is("UNIQUE") {
+			if _, err := p.ident(); err != nil {
+				return err
+			}
+		}
+	}
+	switch {
+	case p.accept("PRIMARY", "KEY"):
+		p.accept("USING", "BTREE")
+		columns, err := p.parseIndexColumns()
+		if err != nil {
+			return err
+		}
+		return p.setPrimaryKey(t, columns, true)
+	case p.accept("UNIQUE"):
+		if !p.accept("KEY") {
+			p.accept("INDEX")
+		}
+		return p.parseIndexDefinition(t, true)
+	case p.accept("KEY"), p.accept("INDEX"):
+		return p.parseIndexDefinition(t, false)
+	case start.kind == ddlIdent:
+		for _, w := range []string{"CONSTRAINT", "FOREIGN", "CHECK", "FULLTEXT", "SPATIAL"} {
+			if start.is(w) {
+				return fmt.Errorf("line %d: unsupported table element: %v", start.line, start)
+			}
+		}
+	}
+	return p.parseColumnDefinition(t)
+}
+
+func (p *ddlParser) parseIndexDefinition(t *dialect.SnapshotTable, unique bool) error {
+	line := p.peek().line
+	var name string
+	if !p.peek().is("(") {
+		var err error
+		if name, err = p.ident(); err != nil {
+			return err
+		}
+	}
+	p.accept("USING", "BTREE")
+	columns, err := p.parseIndexColumns()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = columns[0]
+	}
+	return p.setIndex(t, line, name, columns, unique)
+}
+
+func (p *ddlParser) parseCreateIndex(unique bool) error {
+	name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	// The index name is qualified by the schema of the table implicitly.
+	_, name = dialect.SplitTableName(name)
+	if err := p.expect("ON"); err != nil {
+		return err
+	}
+	line := p.peek().line
+	tableName, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	t := p.table(tableName)
+	if t == nil {
+		return fmt.Errorf("line %d: table `%s' is not defined", line, tableName)
+	}
+	columns, err := p.parseIndexColumns()
+	if err != nil {
+		return err
+	}
+	// The options of the index such as STORING and INTERLEAVE IN of Cloud Spanner are not supported.
+	if option := p.skipStatement(); option != "" {
+		return fmt.Errorf("line %d: unsupported index option: %s", line, option)
+	}
+	return p.setIndex(t, line, name, columns, unique)
+}
+
+func (p *ddlParser) parseIndexColumns() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var columns []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().is("(") {
+			return nil, fmt.Errorf("line %d: index prefix length is not supported: %s", p.peek().line, name)
+		}
+		if !p.accept("ASC") {
+			p.accept("DESC")
+		}
+		columns = append(columns, name)
+		if p.accept(")") {
+			return columns, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *ddlParser) column(t *dialect.SnapshotTable, name string) (*dialect.SnapshotColumn, error) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("line %d: column `%s' is not defined in table `%s'", p.tokens[p.pos-1].line, name, t.Name)
+}
+
+func (p *ddlParser) setPrimaryKey(t *dialect.SnapshotTable, columns []string, notNull bool) error {
+	for _, name := range columns {
+		c, err := p.column(t, name)
+		if err != nil {
+			return err
+		}
+		c.PrimaryKey = true
+		if notNull {
+			c.Nullable = false
+		}
+	}
+	return nil
+}
+
+// setIndex sets the index to the columns.
+// The index whose columns are not in the same order as the table, and the
+// column that has multiple indexes are not supported because Migu handles
+// the index as the attribute of the column.
+func (p *ddlParser) setIndex(t *dialect.SnapshotTable, line int, name string, columns []string, unique bool) error {
+	last := -1
+	for _, column := range columns {
+		c, err := p.column(t, column)
+		if err != nil {
+			return err
+		}
+		if c.Index != nil {
+			return fmt.Errorf("line %d: column `%s' cannot have multiple indexes: `%s' and `%s'", line, column, c.Index.Name, name)
+		}
+		pos := columnPosition(t, column)
+		if pos < last {
+			return fmt.Errorf("line %d: the columns of index `%s' must be in the same order as the columns of table `%s'", line, name, t.Name)
+		}
+		last = pos
+		c.Index = &dialect.SnapshotIndex{
+			Name:   name,
+			Unique: unique,
+		}
+	}
+	return nil
+}
+
+func columnPosition(t *dialect.SnapshotTable, name string) int {
+	for i, c := range t.Columns {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *ddlParser) parseColumnDefinition(t *dialect.SnapshotTable) error {
+	line := p.peek().line
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if _, err := p.column(t, name); err == nil {
+		return fmt.Errorf("line %d: column `%s' is already defined in table `%s'", line, name, t.Name)
+	}
+	typ, err := p.parseColumnType()
+	if err != nil {
+		return err
+	}
+	c := &dialect.SnapshotColumn{
+		Name:     name,
+		Type:     typ,
+		DataType: columnDataType(typ),
+		Nullable: true,
+	}
+	t.Columns = append(t.Columns, c)
+	for {
+		tok := p.peek()
+		switch {
+		case tok.is(",") || tok.is(")"):
+			return nil
+		case p.accept("NOT", "NULL"):
+			c.Nullable = false
+		case p.accept("NULL"):
+			c.Nullable = true
+		case p.accept("DEFAULT"):
+			def, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(def, "NULL") {
+				c.Default = &def
+			}
+		case p.accept("AUTO_INCREMENT"):
+			c.AutoIncrement = true
+		case p.accept("PRIMARY", "KEY"), p.accept("KEY"):
+			c.PrimaryKey = true
+			c.Nullable = false
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			c.Index = &dialect.SnapshotIndex{
+				Name:   name,
+				Unique: true,
+			}
+		case p.accept("COMMENT"):
+			s := p.next()
+			if s.kind != ddlString {
+				p.pos--
+				return p.unexpected("string")
+			}
+			if s.value != "" {
+				c.Comment = &s.value
+			}
+		case p.accept("ON", "UPDATE"):
+			expr, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			extra := "ON UPDATE " + strings.ToUpper(expr)
+			c.Extra = &extra
+		case p.accept("OPTIONS"):
+			extra, err := p.parseColumnOptions()
+			if err != nil {
+				return err
+			}
+			if extra != "" {
+				c.Extra = &extra
+			}
+		case p.accept("CHARACTER", "SET"), p.accept("CHARSET"):
+			charset, err := p.ident()
+			if err != nil {
+				return err
+			}
+			c.Charset = &charset
+		case p.accept("COLLATE"):
+			collation, err := p.ident()
+			if err != nil {
+				return err
+			}
+			c.Collation = &collation
+		default:
+			return fmt.Errorf("line %d: unsupported column definition: %v", tok.line, tok)
+		}
+	}
+}
+
+// parseColumnType parses the column type such as VARCHAR(255), INT UNSIGNED and ARRAY<STRING(MAX)>.
+func (p *ddlParser) parseColumnType() (string, error) {
+	var b strings.Builder
+	name, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(strings.ToUpper(name))
+	for depth := 0; ; {
+		t := p.peek()
+		switch {
+		case t.is("(") || t.is("<"):
+			depth++
+		case (t.is(")") || t.is(">")) && depth > 0:
+			depth--
+		case t.is(",") && depth > 0:
+		case t.kind == ddlIdent && depth > 0:
+		case t.kind == ddlString && depth > 0:
+			// The values of ENUM and SET are case-sensitive.
+			b.WriteString("'" + strings.Replace(t.value, "'", "''", -1) + "'")
+			p.pos++
+			continue
+		case t.is("UNSIGNED") || t.is("SIGNED") || t.is("ZEROFILL"):
+			b.WriteByte(' ')
+		default:
+			if depth > 0 {
+				return "", p.unexpected("column type")
+			}
+			return normalizeColumnType(b.String()), nil
+		}
+		b.WriteString(strings.ToUpper(t.value))
+		p.pos++
+	}
+}
+
+// normalizeColumnType normalizes the column type to the same form as the information schema of MySQL.
+func normalizeColumnType(typ string) string {
+	switch dataType := strings.ToUpper(columnDataType(typ)); dataType {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT":
+		if strings.EqualFold(typ, "TINYINT(1)") {
+			return typ
+		}
+		// The display width of integer types is deprecated as of MySQL 8.0.17.
+		if start, end := strings.IndexByte(typ, '('), strings.IndexByte(typ, ')'); start >= 0 && end > start {
+			typ = typ[:start] + typ[end+1:]
+		}
+	}
+	return typ
+}
+
+// columnDataType returns the data type of the column type in lower case, e.g. varchar for VARCHAR(255).
+func columnDataType(typ string) string {
+	if i := strings.IndexAny(typ, "(< "); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.ToLower(typ)
+}
+
+// parseExpr parses the value of DEFAULT or ON UPDATE.
+// The parentheses of the function call without arguments are trimmed.
+func (p *ddlParser) parseExpr() (string, error) {
+	t := p.peek()
+	switch t.kind {
+	case ddlString:
+		p.pos++
+		return t.value, nil
+	case ddlIdent:
+		p.pos++
+		if !p.peek().is("(") {
+			return t.value, nil
+		}
+		args, err := p.skipParens()
+		if err != nil {
+			return "", err
+		}
+		if args == "()" {
+			return t.value, nil
+		}
+		return t.value + args, nil
+	case ddlSymbol:
+		if t.is("-") || t.is("+") {
+			p.pos++
+			n := p.next()
+			if n.kind != ddlIdent {
+				p.pos--
+				return "", p.unexpected("number")
+			}
+			return t.value + n.value, nil
+		}
+		if t.is("(") {
+			return p.skipParens()
+		}
+	}
+	return "", p.unexpected("expression")
+}
+
+// parseColumnOptions parses OPTIONS of the column of Cloud Spanner, e.g. OPTIONS (allow_commit_timestamp = true).
+func (p *ddlParser) parseColumnOptions() (string, error) {
+	if err := p.expect("("); err != nil {
+		return "", err
+	}
+	var options []string
+	for !p.accept(")") {
+		name, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("="); err != nil {
+			return "", err
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(value, "null") {
+			options = append(options, fmt.Sprintf("%s = %s", name, strings.ToLower(value)))
+		}
+		if !p.accept(",") && !p.peek().is(")") {
+			return "", p.unexpected(", or )")
+		}
+	}
+	return strings.Join(options, ", "), nil
+}
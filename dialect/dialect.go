@@ -1,7 +1,18 @@
 package dialect
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOffline is returned when the dialect in offline mode is requested to access the database.
+var ErrOffline = errors.New("dialect: cannot access the database in offline mode")
+
 type Dialect interface {
 	ColumnSchema(tables ...string) ([]ColumnSchema, error)
+	ColumnSchemaContext(ctx context.Context, tables ...string) ([]ColumnSchema, error)
 	ColumnType(name string) string
 	GoType(name string, nullable bool) string
 	IsNullable(name string) bool
@@ -17,6 +28,26 @@ type Dialect interface {
 	DropIndexSQL(index Index) []string
 
 	Begin() (Transactioner, error)
+	BeginContext(ctx context.Context) (Transactioner, error)
+}
+
+// SplitTableName splits the table name that is qualified by the schema as SCHEMA.TABLE.
+// If name isn't qualified, schema is empty, which means the default schema.
+func SplitTableName(name string) (schema, table string) {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// QuoteTable returns the quoted table name by d.
+// If name is qualified by the schema as SCHEMA.TABLE, both are quoted.
+func QuoteTable(d Dialect, name string) string {
+	schema, table := SplitTableName(name)
+	if schema == "" {
+		return d.Quote(table)
+	}
+	return d.Quote(schema) + "." + d.Quote(table)
 }
 
 type ColumnSchema interface {
@@ -33,12 +64,131 @@ type ColumnSchema interface {
 	Comment() (string, bool)
 }
 
+// CollationColumnSchema is an optional interface for ColumnSchema that is
+// implemented by the column schema which has the character set and collation.
+// Charset and Collation return them unless they are the default of the table.
+type CollationColumnSchema interface {
+	Charset() (string, bool)
+	Collation() (string, bool)
+}
+
+// TableSchema is the schema of the table itself other than its columns.
+type TableSchema interface {
+	TableName() string
+	Comment() (string, bool)
+
+	// Option returns the table option such as ENGINE=InnoDB.
+	Option() (string, bool)
+}
+
+// TableSchemaReader is the interface that is implemented by the dialect which
+// can read the schemas of the tables. If no tables are given, the schemas of
+// all tables are returned.
+type TableSchemaReader interface {
+	TableSchema(tables ...string) ([]TableSchema, error)
+	TableSchemaContext(ctx context.Context, tables ...string) ([]TableSchema, error)
+}
+
+// TableModifier is the interface that is implemented by the dialect which can
+// modify the table itself such as the table comment.
+// ModifyTableSQL returns no SQLs if there is nothing to modify.
+// IsTableDataConverted reports whether the modification converts the data of
+// the table, e.g. by changing the character set, so that the data cannot be
+// restored by modifying the table back.
+type TableModifier interface {
+	ModifyTableSQL(oldTable, newTable Table) []string
+	IsTableDataConverted(oldTable, newTable Table) bool
+}
+
 type Transactioner interface {
 	Exec(sql string, args ...interface{}) error
+	ExecContext(ctx context.Context, sql string, args ...interface{}) error
 	Commit() error
 	Rollback() error
 }
 
+// DDLTransactioner is an optional interface for Transactioner that reports
+// whether the DDL statements executed within the transaction can be rolled back.
+type DDLTransactioner interface {
+	IsDDLTransactional() bool
+}
+
+// BatchTransactioner is an optional interface for Transactioner that reports
+// whether the statements passed to Exec are buffered and submitted in batches
+// by Commit. If so, Exec never executes the statement, and the error of the
+// statement is returned from Commit as *BatchError.
+type BatchTransactioner interface {
+	IsBatched() bool
+}
+
+// BatchError is returned when a statement in the batch of statements has failed.
+type BatchError struct {
+	// Statements is all of the statements that were submitted.
+	Statements []string
+
+	// Index is the index of the failed statement in Statements.
+	// The statements before Index have been applied, and the rest have not.
+	Index int
+
+	// Err is the error that was returned from the database.
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	if e.Index >= len(e.Statements) {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("statement %d of %d failed: %v\n%s", e.Index+1, len(e.Statements), e.Err, e.Statements[e.Index])
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Applied returns the statements that have been applied before the failure.
+func (e *BatchError) Applied() []string {
+	return e.Statements[:e.Index]
+}
+
+// Offliner is the interface that reports whether the dialect is in offline mode.
+// The dialect in offline mode has no connection to the database, so it can be
+// used to generate SQLs only. Its ColumnSchema returns the schemas of the
+// snapshot given by WithSnapshot or no schemas, and its Begin returns ErrOffline.
+type Offliner interface {
+	IsOffline() bool
+}
+
+// TableFilterer is the interface that is implemented by the dialect which has
+// the table filter given by WithTableFilter. Tables that are not targets are
+// ignored by ColumnSchema, and Migu never creates, modifies or drops them.
+type TableFilterer interface {
+	IsTargetTable(name string) bool
+}
+
+// ColumnFilterer is the interface that is implemented by the dialect which has
+// the ignored columns given by WithIgnoreColumns. Migu never adds, modifies or
+// drops the ignored columns and their indexes.
+type ColumnFilterer interface {
+	IsIgnoredColumn(table, column string) bool
+}
+
+// CollationNormalizer is the interface that is implemented by the dialect which
+// supports the character set and collation of the columns.
+// NormalizeCollation returns the character set and collation of the column in
+// the table with the given table options. The later option takes precedence.
+// Both are empty if the column uses the default of the table.
+type CollationNormalizer interface {
+	NormalizeCollation(charset, collation string, tableOptions ...string) (string, string)
+}
+
+// DefaultCollationReader is the interface that is implemented by the dialect
+// which can read the default collation of each character set from the database.
+// The returned map is keyed by the name of the character set.
+type DefaultCollationReader interface {
+	DefaultCollations() (map[string]string, error)
+	DefaultCollationsContext(ctx context.Context) (map[string]string, error)
+}
+
 type PrimaryKeyModifier interface {
 	ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string
 }
@@ -48,6 +198,7 @@ type Table struct {
 	Fields      []Field
 	PrimaryKeys []string
 	Option      string
+	Comment     string
 }
 
 type Field struct {
@@ -59,6 +210,8 @@ type Field struct {
 	Default       string
 	Extra         string
 	Nullable      bool
+	Charset       string
+	Collation     string
 }
 
 type Index struct {
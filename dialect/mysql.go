@@ -1,13 +1,25 @@
 package dialect
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
-var _ PrimaryKeyModifier = &MySQL{}
+var (
+	_ PrimaryKeyModifier  = &MySQL{}
+	_ Offliner            = &MySQL{}
+	_ TableFilterer       = &MySQL{}
+	_ ColumnFilterer      = &MySQL{}
+	_ TableSchemaReader   = &MySQL{}
+	_ TableModifier       = &MySQL{}
+	_ CollationNormalizer = &MySQL{}
+	_ DDLTransactioner    = &mysqlTransaction{}
+)
 
 var (
 	mysqlColumnTypes = []*ColumnType{
@@ -64,11 +76,14 @@ type MySQL struct {
 	db              *sql.DB
 	dbName          string
 	version         *mysqlVersion
+	collations      map[string]string
 	opt             *option
 	columnTypeMap   map[string]*ColumnType
 	nullableTypeMap map[string]struct{}
 }
 
+// NewMySQL returns a new dialect for MySQL/MariaDB.
+// If db is nil, the dialect is in offline mode. See Offliner.
 func NewMySQL(db *sql.DB, opts ...Option) Dialect {
 	d := &MySQL{
 		db:              db,
@@ -93,49 +108,66 @@ func NewMySQL(db *sql.DB, opts ...Option) Dialect {
 }
 
 func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
-	dbname, err := d.currentDBName()
+	return d.ColumnSchemaContext(context.Background(), tables...)
+}
+
+func (d *MySQL) ColumnSchemaContext(ctx context.Context, tables ...string) ([]ColumnSchema, error) {
+	schemas, err := d.columnSchema(ctx, tables...)
 	if err != nil {
 		return nil, err
 	}
-	version, err := d.dbVersion()
+	return d.opt.filterColumnSchemas(schemas), nil
+}
+
+func (d *MySQL) columnSchema(ctx context.Context, tables ...string) ([]ColumnSchema, error) {
+	if d.IsOffline() {
+		return d.opt.snapshot.ColumnSchema(tables...), nil
+	}
+	dbname, err := d.currentDBName(ctx)
 	if err != nil {
 		return nil, err
 	}
-	indexMap, err := d.getIndexMap()
+	version, err := d.dbVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The default collations are used by NormalizeCollation to compare the columns.
+	if _, err := d.DefaultCollationsContext(ctx); err != nil {
+		return nil, err
+	}
+	tableSet := newMySQLTableSet(dbname, tables)
+	indexMap, err := d.getIndexMap(ctx, tableSet.schemas)
 	if err != nil {
 		return nil, err
 	}
 	parts := []string{
 		"SELECT",
-		"  TABLE_NAME,",
-		"  COLUMN_NAME,",
-		"  COLUMN_DEFAULT,",
-		"  IS_NULLABLE,",
-		"  DATA_TYPE,",
-		"  CHARACTER_MAXIMUM_LENGTH,",
-		"  CHARACTER_OCTET_LENGTH,",
-		"  NUMERIC_PRECISION,",
-		"  NUMERIC_SCALE,",
-		"  DATETIME_PRECISION,",
-		"  COLUMN_TYPE,",
-		"  COLUMN_KEY,",
-		"  EXTRA,",
-		"  COLUMN_COMMENT",
-		"FROM information_schema.COLUMNS",
-		"WHERE TABLE_SCHEMA = ?",
-	}
-	args := []interface{}{dbname}
-	if len(tables) > 0 {
-		placeholder := strings.Repeat(",?", len(tables))
-		placeholder = placeholder[1:] // truncate the heading comma.
-		parts = append(parts, fmt.Sprintf("AND TABLE_NAME IN (%s)", placeholder))
-		for _, t := range tables {
-			args = append(args, t)
-		}
+		"  c.TABLE_SCHEMA,",
+		"  c.TABLE_NAME,",
+		"  c.COLUMN_NAME,",
+		"  c.COLUMN_DEFAULT,",
+		"  c.IS_NULLABLE,",
+		"  c.DATA_TYPE,",
+		"  c.CHARACTER_MAXIMUM_LENGTH,",
+		"  c.CHARACTER_OCTET_LENGTH,",
+		"  c.NUMERIC_PRECISION,",
+		"  c.NUMERIC_SCALE,",
+		"  c.DATETIME_PRECISION,",
+		"  c.COLUMN_TYPE,",
+		"  c.COLUMN_KEY,",
+		"  c.EXTRA,",
+		"  c.COLUMN_COMMENT,",
+		"  c.CHARACTER_SET_NAME,",
+		"  c.COLLATION_NAME,",
+		"  t.TABLE_COLLATION",
+		"FROM information_schema.COLUMNS AS c",
+		"LEFT JOIN information_schema.TABLES AS t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME",
 	}
-	parts = append(parts, "ORDER BY TABLE_NAME, ORDINAL_POSITION")
+	where, args := tableSet.where("c")
+	parts = append(parts, "WHERE "+where)
+	parts = append(parts, "ORDER BY c.TABLE_SCHEMA, c.TABLE_NAME, c.ORDINAL_POSITION")
 	query := strings.Join(parts, "\n")
-	rows, err := d.db.Query(query, args...)
+	rows, err := d.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -145,7 +177,9 @@ func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
 		schema := &mysqlColumnSchema{
 			version: version,
 		}
+		var schemaName string
 		if err := rows.Scan(
+			&schemaName,
 			&schema.tableName,
 			&schema.columnName,
 			&schema.columnDefault,
@@ -160,15 +194,95 @@ func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
 			&schema.columnKey,
 			&schema.extra,
 			&schema.columnComment,
+			&schema.characterSetName,
+			&schema.collationName,
+			&schema.tableCollation,
 		); err != nil {
 			return nil, err
 		}
-		if tableIndex, exists := indexMap[schema.tableName]; exists {
+		key := mysqlTableKey{schemaName, schema.tableName}
+		if tableIndex, exists := indexMap[key]; exists {
 			if info, exists := tableIndex[schema.columnName]; exists {
 				schema.nonUnique = info.NonUnique
 				schema.indexName = info.IndexName
 			}
 		}
+		schema.tableName = tableSet.tableName(key)
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *MySQL) TableSchema(tables ...string) ([]TableSchema, error) {
+	return d.TableSchemaContext(context.Background(), tables...)
+}
+
+func (d *MySQL) TableSchemaContext(ctx context.Context, tables ...string) ([]TableSchema, error) {
+	var schemas []TableSchema
+	if d.IsOffline() {
+		schemas = d.opt.snapshot.TableSchema(tables...)
+	} else {
+		var err error
+		if schemas, err = d.tableSchema(ctx, tables...); err != nil {
+			return nil, err
+		}
+	}
+	filtered := make([]TableSchema, 0, len(schemas))
+	for _, schema := range schemas {
+		if d.opt.isTargetTable(schema.TableName()) {
+			filtered = append(filtered, schema)
+		}
+	}
+	return filtered, nil
+}
+
+func (d *MySQL) tableSchema(ctx context.Context, tables ...string) ([]TableSchema, error) {
+	dbname, err := d.currentDBName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tableSet := newMySQLTableSet(dbname, tables)
+	where, args := tableSet.where("")
+	query := strings.Join([]string{
+		"SELECT",
+		"  TABLE_SCHEMA,",
+		"  TABLE_NAME,",
+		"  ENGINE,",
+		"  ROW_FORMAT,",
+		"  TABLE_COLLATION,",
+		"  a.CHARACTER_SET_NAME,",
+		"  CREATE_OPTIONS,",
+		"  TABLE_COMMENT",
+		"FROM information_schema.TABLES",
+		"LEFT JOIN information_schema.COLLATION_CHARACTER_SET_APPLICABILITY AS a ON a.COLLATION_NAME = TABLE_COLLATION",
+		"WHERE TABLE_TYPE = 'BASE TABLE' AND (" + where + ")",
+		"ORDER BY TABLE_SCHEMA, TABLE_NAME",
+	}, "\n")
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schemas []TableSchema
+	for rows.Next() {
+		var key mysqlTableKey
+		schema := &mysqlTableSchema{}
+		if err := rows.Scan(
+			&key.schema,
+			&key.table,
+			&schema.engine,
+			&schema.rowFormat,
+			&schema.tableCollation,
+			&schema.tableCharset,
+			&schema.createOptions,
+			&schema.tableComment,
+		); err != nil {
+			return nil, err
+		}
+		schema.tableName = tableSet.tableName(key)
 		schemas = append(schemas, schema)
 	}
 	if err := rows.Err(); err != nil {
@@ -241,23 +355,226 @@ func (d *MySQL) CreateTableSQL(table Table) []string {
 	}
 	query := fmt.Sprintf("CREATE TABLE %s (\n"+
 		"  %s\n"+
-		")", d.Quote(table.Name), strings.Join(columns, ",\n  "))
+		")", QuoteTable(d, table.Name), strings.Join(columns, ",\n  "))
 	if table.Option != "" {
 		query += " " + table.Option
 	}
+	if table.Comment != "" {
+		query += " COMMENT " + d.QuoteString(table.Comment)
+	}
 	return []string{query}
 }
 
+// ModifyTableSQL returns the SQL to modify the table options and the table comment.
+// ENGINE, ROW_FORMAT, the default character set and collation are compared only if
+// they are specified in both tables, because the omitted option is unknown.
+// The change of the character set converts all columns into it.
+func (d *MySQL) ModifyTableSQL(oldTable, newTable Table) []string {
+	oldOptions, newOptions := parseMySQLTableOption(oldTable.Option), parseMySQLTableOption(newTable.Option)
+	isDifferent := func(key string) bool {
+		o, ok1 := oldOptions[key]
+		n, ok2 := newOptions[key]
+		return ok1 && ok2 && normalizeMySQLOptionValue(o) != normalizeMySQLOptionValue(n)
+	}
+	var specs []string
+	for _, key := range []string{"ENGINE", "ROW_FORMAT"} {
+		if isDifferent(key) {
+			specs = append(specs, key+"="+newOptions[key])
+		}
+	}
+	if d.IsTableDataConverted(oldTable, newTable) {
+		spec := "CONVERT TO CHARACTER SET " + newOptions["CHARSET"]
+		if collation, ok := newOptions["COLLATE"]; ok {
+			spec += " COLLATE " + collation
+		}
+		specs = append(specs, spec)
+	}
+	if oldTable.Comment != newTable.Comment {
+		specs = append(specs, "COMMENT "+d.QuoteString(newTable.Comment))
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s %s", QuoteTable(d, newTable.Name), strings.Join(specs, ", "))}
+}
+
+// IsTableDataConverted returns true if the character set or the collation of the table is changed
+// because the columns are converted by CONVERT TO CHARACTER SET.
+func (d *MySQL) IsTableDataConverted(oldTable, newTable Table) bool {
+	oldOptions, newOptions := parseMySQLTableOption(oldTable.Option), parseMySQLTableOption(newTable.Option)
+	for _, key := range []string{"CHARSET", "COLLATE"} {
+		o, ok1 := oldOptions[key]
+		n, ok2 := newOptions[key]
+		if ok1 && ok2 && normalizeMySQLOptionValue(o) != normalizeMySQLOptionValue(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCollations returns the default collation of each character set.
+func (d *MySQL) DefaultCollations() (map[string]string, error) {
+	return d.DefaultCollationsContext(context.Background())
+}
+
+// DefaultCollationsContext returns the default collation of each character set.
+// In offline mode, it returns the ones in the snapshot.
+func (d *MySQL) DefaultCollationsContext(ctx context.Context) (map[string]string, error) {
+	if d.IsOffline() {
+		return d.opt.snapshot.defaultCollations(), nil
+	}
+	if d.collations != nil {
+		return d.collations, nil
+	}
+	rows, err := d.db.QueryContext(ctx, `SELECT CHARACTER_SET_NAME, DEFAULT_COLLATE_NAME FROM information_schema.CHARACTER_SETS`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	collations := map[string]string{}
+	for rows.Next() {
+		var charset, collation string
+		if err := rows.Scan(&charset, &collation); err != nil {
+			return nil, err
+		}
+		collations[charset] = collation
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	d.collations = collations
+	return d.collations, nil
+}
+
+// defaultCollation returns the default collation of the character set.
+// It returns an empty string if it's unknown, e.g. in offline mode without the snapshot.
+func (d *MySQL) defaultCollation(charset string) string {
+	collations := d.collations
+	if d.IsOffline() {
+		collations = d.opt.snapshot.defaultCollations()
+	}
+	for c, collation := range collations {
+		if normalizeMySQLOptionValue(c) == normalizeMySQLOptionValue(charset) {
+			return collation
+		}
+	}
+	return ""
+}
+
+// canonicalCollation returns the character set and the collation to compare.
+// The collation is empty if it's the default of the character set.
+func (d *MySQL) canonicalCollation(charset, collation string) (string, string) {
+	charset, collation = normalizeMySQLOptionValue(charset), normalizeMySQLOptionValue(collation)
+	if charset == "" && collation != "" {
+		charset = normalizeMySQLOptionValue(mysqlCharset(collation))
+	}
+	if collation != "" && collation == normalizeMySQLOptionValue(d.defaultCollation(charset)) {
+		collation = ""
+	}
+	return charset, collation
+}
+
+// NormalizeCollation returns the character set and collation of the column to compare.
+// Both are empty if they are the same as the default of the table.
+// The collation is empty if it's the default of the character set, which is
+// read from the database or the snapshot.
+func (d *MySQL) NormalizeCollation(charset, collation string, tableOptions ...string) (string, string) {
+	charset, collation = strings.ToLower(charset), strings.ToLower(collation)
+	if charset == "" && collation == "" {
+		return "", ""
+	}
+	if charset == "" {
+		charset = mysqlCharset(collation)
+	}
+	var defaultCharset, defaultCollation string
+	for _, option := range tableOptions {
+		options := parseMySQLTableOption(option)
+		c, ok := options["CHARSET"]
+		if !ok {
+			continue
+		}
+		if normalizeMySQLOptionValue(c) != normalizeMySQLOptionValue(defaultCharset) {
+			defaultCollation = ""
+		}
+		defaultCharset = c
+		if c, ok := options["COLLATE"]; ok {
+			defaultCollation = c
+		}
+	}
+	cs, cl := d.canonicalCollation(charset, collation)
+	tcs, tcl := d.canonicalCollation(defaultCharset, defaultCollation)
+	if cs == tcs && cl == tcl {
+		return "", ""
+	}
+	// If the default collation of the character set is unknown, the character
+	// set without the collation is regarded as the default of the table.
+	if cs == tcs && (cl == "" || tcl == "") && d.defaultCollation(cs) == "" {
+		return "", ""
+	}
+	if cl == "" {
+		return charset, ""
+	}
+	return charset, collation
+}
+
+// parseMySQLTableOption parses the table option such as "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4".
+// It returns ENGINE, ROW_FORMAT, CHARSET and COLLATE options only.
+// If CHARSET is omitted but COLLATE is given, CHARSET is derived from COLLATE.
+func parseMySQLTableOption(option string) map[string]string {
+	words := strings.FieldsFunc(option, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '=' || r == ','
+	})
+	options := map[string]string{}
+	for i := 0; i+1 < len(words); i++ {
+		key := strings.ToUpper(words[i])
+		if key == "CHARACTER" && strings.EqualFold(words[i+1], "SET") && i+2 < len(words) {
+			key = "CHARSET"
+			i++
+		}
+		switch key {
+		case "ENGINE", "ROW_FORMAT", "CHARSET", "COLLATE":
+			i++
+			options[key] = strings.Trim(words[i], "'\"`")
+		}
+	}
+	if collation, ok := options["COLLATE"]; ok {
+		if _, ok := options["CHARSET"]; !ok {
+			options["CHARSET"] = mysqlCharset(collation)
+		}
+	}
+	return options
+}
+
+// mysqlCharset returns the character set of the collation written in the table option or the field tag.
+// The name of the collation of MySQL starts with the name of its character set.
+// The character set of the collation on the database is read from information_schema instead.
+func mysqlCharset(collation string) string {
+	if i := strings.IndexByte(collation, '_'); i >= 0 {
+		return collation[:i]
+	}
+	return collation
+}
+
+// normalizeMySQLOptionValue returns the value of the table option to compare.
+// The values are case-insensitive, and utf8 is an alias for utf8mb3 that newer servers report.
+func normalizeMySQLOptionValue(name string) string {
+	name = strings.ToLower(name)
+	if name == "utf8" || strings.HasPrefix(name, "utf8_") {
+		return "utf8mb3" + name[len("utf8"):]
+	}
+	return name
+}
+
 func (d *MySQL) AddColumnSQL(field Field) []string {
-	return []string{fmt.Sprintf("ALTER TABLE %s ADD %s", d.Quote(field.Table), d.columnSQL(field))}
+	return []string{fmt.Sprintf("ALTER TABLE %s ADD %s", QuoteTable(d, field.Table), d.columnSQL(field))}
 }
 
 func (d *MySQL) DropColumnSQL(field Field) []string {
-	return []string{fmt.Sprintf("ALTER TABLE %s DROP %s", d.Quote(field.Table), d.Quote(field.Name))}
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP %s", QuoteTable(d, field.Table), d.Quote(field.Name))}
 }
 
 func (d *MySQL) ModifyColumnSQL(oldField, newField Field) []string {
-	return []string{fmt.Sprintf("ALTER TABLE %s CHANGE %s %s", d.Quote(newField.Table), d.Quote(oldField.Name), d.columnSQL(newField))}
+	return []string{fmt.Sprintf("ALTER TABLE %s CHANGE %s %s", QuoteTable(d, newField.Table), d.Quote(oldField.Name), d.columnSQL(newField))}
 }
 
 func (d *MySQL) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
@@ -271,12 +588,14 @@ func (d *MySQL) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []st
 	if len(oldPrimaryKeys) > 0 {
 		specs = append(specs, "DROP PRIMARY KEY")
 	}
-	pkColumns := make([]string, len(newPrimaryKeys))
-	for i, pk := range newPrimaryKeys {
-		pkColumns[i] = d.Quote(pk.Name)
+	if len(newPrimaryKeys) > 0 {
+		pkColumns := make([]string, len(newPrimaryKeys))
+		for i, pk := range newPrimaryKeys {
+			pkColumns[i] = d.Quote(pk.Name)
+		}
+		specs = append(specs, fmt.Sprintf("ADD PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
 	}
-	specs = append(specs, fmt.Sprintf("ADD PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
-	return []string{fmt.Sprintf("ALTER TABLE %s %s", d.Quote(tableName), strings.Join(specs, ", "))}
+	return []string{fmt.Sprintf("ALTER TABLE %s %s", QuoteTable(d, tableName), strings.Join(specs, ", "))}
 }
 
 func (d *MySQL) CreateIndexSQL(index Index) []string {
@@ -285,7 +604,7 @@ func (d *MySQL) CreateIndexSQL(index Index) []string {
 		columns[i] = d.Quote(c)
 	}
 	indexName := d.Quote(index.Name)
-	tableName := d.Quote(index.Table)
+	tableName := QuoteTable(d, index.Table)
 	column := strings.Join(columns, ",")
 	if index.Unique {
 		return []string{fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, column)}
@@ -294,11 +613,17 @@ func (d *MySQL) CreateIndexSQL(index Index) []string {
 }
 
 func (d *MySQL) DropIndexSQL(index Index) []string {
-	return []string{fmt.Sprintf("DROP INDEX %s ON %s", d.Quote(index.Name), d.Quote(index.Table))}
+	return []string{fmt.Sprintf("DROP INDEX %s ON %s", d.Quote(index.Name), QuoteTable(d, index.Table))}
 }
 
 func (d *MySQL) columnSQL(f Field) string {
 	column := []string{d.Quote(f.Name), f.Type}
+	if f.Charset != "" {
+		column = append(column, "CHARACTER SET", f.Charset)
+	}
+	if f.Collation != "" {
+		column = append(column, "COLLATE", f.Collation)
+	}
 	if !f.Nullable {
 		column = append(column, "NOT NULL")
 	}
@@ -331,7 +656,14 @@ func (d *MySQL) isTextType(f Field) bool {
 }
 
 func (d *MySQL) Begin() (Transactioner, error) {
-	tx, err := d.db.Begin()
+	return d.BeginContext(context.Background())
+}
+
+func (d *MySQL) BeginContext(ctx context.Context) (Transactioner, error) {
+	if d.IsOffline() {
+		return nil, ErrOffline
+	}
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -340,6 +672,18 @@ func (d *MySQL) Begin() (Transactioner, error) {
 	}, nil
 }
 
+func (d *MySQL) IsTargetTable(name string) bool {
+	return d.opt.isTargetTable(name)
+}
+
+func (d *MySQL) IsIgnoredColumn(table, column string) bool {
+	return d.opt.isIgnoredColumn(table, column)
+}
+
+func (d *MySQL) IsOffline() bool {
+	return d.db == nil
+}
+
 func (d *MySQL) defaultColumnType(name string) string {
 	switch name := strings.ToUpper(name); name {
 	case "BIT":
@@ -360,22 +704,33 @@ func (d *MySQL) defaultColumnType(name string) string {
 	return name
 }
 
-func (d *MySQL) currentDBName() (string, error) {
+func (d *MySQL) currentDBName(ctx context.Context) (string, error) {
 	if d.dbName != "" {
 		return d.dbName, nil
 	}
-	err := d.db.QueryRow(`SELECT DATABASE()`).Scan(&d.dbName)
+	err := d.db.QueryRowContext(ctx, `SELECT DATABASE()`).Scan(&d.dbName)
 	return d.dbName, err
 }
 
-func (d *MySQL) dbVersion() (*mysqlVersion, error) {
+func (d *MySQL) dbVersion(ctx context.Context) (*mysqlVersion, error) {
 	if d.version != nil {
 		return d.version, nil
 	}
-	var version string
-	if err := d.db.QueryRow(`SELECT VERSION()`).Scan(&version); err != nil {
+	version := d.opt.serverVersion
+	if version == "" {
+		if err := d.db.QueryRowContext(ctx, `SELECT VERSION()`).Scan(&version); err != nil {
+			return nil, err
+		}
+	}
+	v, err := parseMySQLVersion(version)
+	if err != nil {
 		return nil, err
 	}
+	d.version = v
+	return d.version, nil
+}
+
+func parseMySQLVersion(version string) (*mysqlVersion, error) {
 	vs := strings.Split(version, "-")
 	vStr := vs[0]
 	var v mysqlVersion
@@ -383,6 +738,9 @@ func (d *MySQL) dbVersion() (*mysqlVersion, error) {
 		v.Name = vs[1]
 	}
 	versions := strings.Split(vStr, ".")
+	if len(versions) < 3 {
+		return nil, fmt.Errorf("invalid server version: %s", version)
+	}
 	var err error
 	if v.Major, err = strconv.Atoi(versions[0]); err != nil {
 		return nil, err
@@ -393,47 +751,127 @@ func (d *MySQL) dbVersion() (*mysqlVersion, error) {
 	if v.Patch, err = strconv.Atoi(versions[2]); err != nil {
 		return nil, err
 	}
-	d.version = &v
-	return d.version, err
+	return &v, nil
 }
 
-func (d *MySQL) getIndexMap() (map[string]map[string]mysqlIndexInfo, error) {
-	dbname, err := d.currentDBName()
-	if err != nil {
-		return nil, err
-	}
+func (d *MySQL) getIndexMap(ctx context.Context, schemaNames []string) (map[mysqlTableKey]map[string]mysqlIndexInfo, error) {
+	placeholder := strings.Repeat(",?", len(schemaNames))
+	placeholder = placeholder[1:] // truncate the heading comma.
 	query := strings.Join([]string{
 		"SELECT",
+		"  TABLE_SCHEMA,",
 		"  TABLE_NAME,",
 		"  COLUMN_NAME,",
 		"  NON_UNIQUE,",
 		"  INDEX_NAME",
 		"FROM information_schema.STATISTICS",
-		"WHERE TABLE_SCHEMA = ?",
+		fmt.Sprintf("WHERE TABLE_SCHEMA IN (%s)", placeholder),
 	}, "\n")
-	rows, err := d.db.Query(query, dbname)
+	args := make([]interface{}, len(schemaNames))
+	for i, schemaName := range schemaNames {
+		args[i] = schemaName
+	}
+	rows, err := d.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	indexMap := make(map[string]map[string]mysqlIndexInfo)
+	indexMap := make(map[mysqlTableKey]map[string]mysqlIndexInfo)
 	for rows.Next() {
 		var (
-			tableName  string
+			key        mysqlTableKey
 			columnName string
 			index      mysqlIndexInfo
 		)
-		if err := rows.Scan(&tableName, &columnName, &index.NonUnique, &index.IndexName); err != nil {
+		if err := rows.Scan(&key.schema, &key.table, &columnName, &index.NonUnique, &index.IndexName); err != nil {
 			return nil, err
 		}
-		if _, exists := indexMap[tableName]; !exists {
-			indexMap[tableName] = make(map[string]mysqlIndexInfo)
+		if _, exists := indexMap[key]; !exists {
+			indexMap[key] = make(map[string]mysqlIndexInfo)
 		}
-		indexMap[tableName][columnName] = index
+		indexMap[key][columnName] = index
 	}
 	return indexMap, rows.Err()
 }
 
+type mysqlTableKey struct {
+	schema string
+	table  string
+}
+
+// mysqlTableSet is the set of the tables that are grouped by the schema (database).
+// The unqualified tables belong to the current database.
+type mysqlTableSet struct {
+	dbname  string
+	schemas []string
+	tables  map[string][]string
+	names   map[mysqlTableKey]string
+}
+
+// newMySQLTableSet returns a new mysqlTableSet of tables.
+// If no tables are given, it means all tables of the current database.
+func newMySQLTableSet(dbname string, tables []string) *mysqlTableSet {
+	s := &mysqlTableSet{
+		dbname:  dbname,
+		schemas: []string{dbname},
+		tables:  map[string][]string{},
+		names:   map[mysqlTableKey]string{},
+	}
+	if len(tables) == 0 {
+		return s
+	}
+	for _, t := range tables {
+		schemaName, tableName := SplitTableName(t)
+		if schemaName == "" {
+			schemaName = dbname
+		}
+		s.tables[schemaName] = append(s.tables[schemaName], tableName)
+		s.names[mysqlTableKey{schemaName, tableName}] = t
+	}
+	s.schemas = make([]string, 0, len(s.tables))
+	for schemaName := range s.tables {
+		s.schemas = append(s.schemas, schemaName)
+	}
+	sort.Strings(s.schemas)
+	return s
+}
+
+// where returns the condition of the WHERE clause and its arguments to select the tables.
+// If alias isn't empty, the columns are qualified by it.
+func (s *mysqlTableSet) where(alias string) (string, []interface{}) {
+	prefix := ""
+	if alias != "" {
+		prefix = alias + "."
+	}
+	if len(s.tables) == 0 {
+		return prefix + "TABLE_SCHEMA = ?", []interface{}{s.dbname}
+	}
+	var args []interface{}
+	conds := make([]string, len(s.schemas))
+	for i, schemaName := range s.schemas {
+		placeholder := strings.Repeat(",?", len(s.tables[schemaName]))
+		placeholder = placeholder[1:] // truncate the heading comma.
+		conds[i] = fmt.Sprintf("(%sTABLE_SCHEMA = ? AND %sTABLE_NAME IN (%s))", prefix, prefix, placeholder)
+		args = append(args, schemaName)
+		for _, t := range s.tables[schemaName] {
+			args = append(args, t)
+		}
+	}
+	return strings.Join(conds, " OR "), args
+}
+
+// tableName returns the name of the table as it was given.
+// The table in the schema other than the current database is qualified by the schema.
+func (s *mysqlTableSet) tableName(key mysqlTableKey) string {
+	if name, ok := s.names[key]; ok {
+		return name
+	}
+	if key.schema != s.dbname {
+		return key.schema + "." + key.table
+	}
+	return key.table
+}
+
 type mysqlIndexInfo struct {
 	NonUnique int64
 	IndexName string
@@ -451,7 +889,11 @@ type mysqlTransaction struct {
 }
 
 func (m *mysqlTransaction) Exec(sql string, args ...interface{}) error {
-	_, err := m.tx.Exec(sql, args...)
+	return m.ExecContext(context.Background(), sql, args...)
+}
+
+func (m *mysqlTransaction) ExecContext(ctx context.Context, sql string, args ...interface{}) error {
+	_, err := m.tx.ExecContext(ctx, sql, args...)
 	return err
 }
 
@@ -463,6 +905,11 @@ func (m *mysqlTransaction) Rollback() error {
 	return m.tx.Rollback()
 }
 
+// IsDDLTransactional returns false because MySQL commits DDL statements implicitly.
+func (m *mysqlTransaction) IsDDLTransactional() bool {
+	return false
+}
+
 func trimParens(s string) string {
 	start, end := -1, -1
 	for i := 0; i < len(s); i++ {
@@ -482,7 +929,10 @@ func trimParens(s string) string {
 	return s[:start] + s[end+1:]
 }
 
-var _ ColumnSchema = &mysqlColumnSchema{}
+var (
+	_ ColumnSchema          = &mysqlColumnSchema{}
+	_ CollationColumnSchema = &mysqlColumnSchema{}
+)
 
 type mysqlColumnSchema struct {
 	tableName              string
@@ -500,6 +950,9 @@ type mysqlColumnSchema struct {
 	columnKey              string
 	extra                  string
 	columnComment          string
+	characterSetName       sql.NullString
+	collationName          sql.NullString
+	tableCollation         sql.NullString
 	nonUnique              int64
 	indexName              string
 
@@ -593,6 +1046,75 @@ func (schema *mysqlColumnSchema) Comment() (string, bool) {
 	return schema.columnComment, schema.columnComment != ""
 }
 
+var _ TableSchema = &mysqlTableSchema{}
+
+type mysqlTableSchema struct {
+	tableName      string
+	engine         sql.NullString
+	rowFormat      sql.NullString
+	tableCollation sql.NullString
+	tableCharset   sql.NullString
+	createOptions  sql.NullString
+	tableComment   string
+}
+
+func (schema *mysqlTableSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *mysqlTableSchema) Comment() (string, bool) {
+	return schema.tableComment, schema.tableComment != ""
+}
+
+func (schema *mysqlTableSchema) Option() (string, bool) {
+	var options []string
+	if schema.engine.String != "" {
+		options = append(options, "ENGINE="+schema.engine.String)
+	}
+	if collation := schema.tableCollation.String; collation != "" {
+		charset := schema.tableCharset.String
+		if charset == "" {
+			// The collation that isn't in COLLATION_CHARACTER_SET_APPLICABILITY by its full name, e.g. UCA 14.0.0 collations of MariaDB.
+			charset = mysqlCharset(collation)
+		}
+		options = append(options, "DEFAULT CHARSET="+charset, "COLLATE="+collation)
+	}
+	if schema.rowFormat.String != "" {
+		options = append(options, "ROW_FORMAT="+strings.ToUpper(schema.rowFormat.String))
+	}
+	for _, option := range strings.Fields(schema.createOptions.String) {
+		kv := strings.SplitN(option, "=", 2)
+		// ROW_FORMAT is already given, and "partitioned" is not a table option.
+		if len(kv) < 2 || strings.EqualFold(kv[0], "row_format") {
+			continue
+		}
+		options = append(options, strings.ToUpper(kv[0])+"="+kv[1])
+	}
+	option := strings.Join(options, " ")
+	return option, option != ""
+}
+
+func (schema *mysqlColumnSchema) Charset() (string, bool) {
+	if schema.isTableCollation() {
+		return "", false
+	}
+	return schema.characterSetName.String, schema.characterSetName.String != ""
+}
+
+// Collation returns the collation of the column unless it is the default of the table.
+// The default collation of the character set is returned as it is, and it's
+// normalized by NormalizeCollation in the same way as the one of the field tag.
+func (schema *mysqlColumnSchema) Collation() (string, bool) {
+	if schema.isTableCollation() {
+		return "", false
+	}
+	return schema.collationName.String, schema.collationName.String != ""
+}
+
+func (schema *mysqlColumnSchema) isTableCollation() bool {
+	return schema.collationName.String == schema.tableCollation.String
+}
+
 func (schema *mysqlColumnSchema) isUnsigned() bool {
 	return strings.Contains(schema.columnType, "unsigned")
 }
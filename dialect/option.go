@@ -1,10 +1,28 @@
 package dialect
 
+import (
+	"path"
+	"strings"
+	"time"
+)
+
 // Option configures settings for computing differences of schemas.
 type Option func(*option)
 
 type option struct {
-	columnTypes []*ColumnType
+	columnTypes   []*ColumnType
+	ddlBatchSize  int
+	serverVersion string
+	snapshot      *Snapshot
+
+	endpoint        string
+	credentialsFile string
+	dialTimeout     time.Duration
+	emulatorHost    string
+
+	includeTables []string
+	excludeTables []string
+	ignoreColumns []string
 }
 
 func newOption() *option {
@@ -17,3 +35,132 @@ func WithColumnType(columnTypes []*ColumnType) Option {
 		o.columnTypes = columnTypes
 	}
 }
+
+// WithDDLBatchSize sets the maximum number of DDL statements that are submitted at once.
+// Zero or negative size means that all statements are submitted in a single batch.
+// This option is currently used by Cloud Spanner only.
+func WithDDLBatchSize(size int) Option {
+	return func(o *option) {
+		o.ddlBatchSize = size
+	}
+}
+
+// WithServerVersion sets the version of the database server, e.g. "8.0.34" or "10.5.8-MariaDB".
+// If it is set, the version is not queried from the database.
+// The version is used only to interpret the schema read from the database,
+// so it has no effect in offline mode.
+// This option is currently used by MySQL/MariaDB only.
+func WithServerVersion(version string) Option {
+	return func(o *option) {
+		o.serverVersion = version
+	}
+}
+
+// WithSnapshot sets the snapshot of the database schema.
+// The dialect in offline mode reads the schema from the snapshot instead of the database.
+func WithSnapshot(snapshot *Snapshot) Option {
+	return func(o *option) {
+		o.snapshot = snapshot
+	}
+}
+
+// WithEndpoint sets the endpoint of the API instead of the default.
+// This option is currently used by Cloud Spanner only.
+func WithEndpoint(endpoint string) Option {
+	return func(o *option) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithCredentialsFile sets the service account key file or the refresh token JSON credentials file.
+// If it is not set, the Application Default Credentials are used.
+// This option is currently used by Cloud Spanner only.
+func WithCredentialsFile(filename string) Option {
+	return func(o *option) {
+		o.credentialsFile = filename
+	}
+}
+
+// WithDialTimeout sets the timeout for establishing the connection.
+// Zero or negative timeout means the default of one second.
+// This option is currently used by Cloud Spanner only.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(o *option) {
+		o.dialTimeout = timeout
+	}
+}
+
+// WithEmulator connects to the emulator at host without authentication.
+// If it is not set, the SPANNER_EMULATOR_HOST environment variable is used.
+// This option is currently used by Cloud Spanner only.
+func WithEmulator(host string) Option {
+	return func(o *option) {
+		o.emulatorHost = host
+	}
+}
+
+// WithTableFilter sets the glob patterns of the tables that are targets of Migu.
+// A table is a target if its name matches any of include, or include is empty,
+// and doesn't match any of exclude. The pattern syntax is the same as path.Match.
+// See TableFilterer.
+func WithTableFilter(include, exclude []string) Option {
+	return func(o *option) {
+		o.includeTables = include
+		o.excludeTables = exclude
+	}
+}
+
+// WithIgnoreColumns sets the columns that are managed by others, e.g. the columns added by CDC tools.
+// Each column is TABLE.COLUMN, and both of TABLE and COLUMN can be the glob pattern of path.Match.
+// TABLE can be qualified by the schema as SCHEMA.TABLE because COLUMN is after the last dot.
+// See ColumnFilterer.
+func WithIgnoreColumns(columns []string) Option {
+	return func(o *option) {
+		o.ignoreColumns = columns
+	}
+}
+
+func (o *option) isIgnoredColumn(table, column string) bool {
+	for _, c := range o.ignoreColumns {
+		i := strings.LastIndex(c, ".")
+		if i < 0 {
+			continue
+		}
+		if matched, _ := path.Match(c[:i], table); !matched {
+			continue
+		}
+		if matched, _ := path.Match(c[i+1:], column); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *option) isTargetTable(name string) bool {
+	if len(o.includeTables) > 0 && !matchTable(o.includeTables, name) {
+		return false
+	}
+	return !matchTable(o.excludeTables, name)
+}
+
+func (o *option) filterColumnSchemas(schemas []ColumnSchema) []ColumnSchema {
+	if len(o.includeTables) == 0 && len(o.excludeTables) == 0 {
+		return schemas
+	}
+	filtered := make([]ColumnSchema, 0, len(schemas))
+	for _, schema := range schemas {
+		if o.isTargetTable(schema.TableName()) {
+			filtered = append(filtered, schema)
+		}
+	}
+	return filtered
+}
+
+func matchTable(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+package dialect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Snapshot is the schema of the database that can be saved to the file.
+// The dialect in offline mode reads the schema from the snapshot given by WithSnapshot.
+type Snapshot struct {
+	Tables []*SnapshotTable `json:"tables" yaml:"tables"`
+
+	// DefaultCollations is the default collation of each character set of the database.
+	// It's used to compare the collations of the columns in offline mode.
+	DefaultCollations map[string]string `json:"default_collations,omitempty" yaml:"default_collations,omitempty"`
+}
+
+// SnapshotTable is the table in the snapshot.
+type SnapshotTable struct {
+	Name    string            `json:"name" yaml:"name"`
+	Comment *string           `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Option  string            `json:"option,omitempty" yaml:"option,omitempty"`
+	Columns []*SnapshotColumn `json:"columns" yaml:"columns"`
+}
+
+// SnapshotColumn is the column in the snapshot.
+type SnapshotColumn struct {
+	Name          string         `json:"name" yaml:"name"`
+	Type          string         `json:"type" yaml:"type"`
+	DataType      string         `json:"data_type" yaml:"data_type"`
+	PrimaryKey    bool           `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+	AutoIncrement bool           `json:"auto_increment,omitempty" yaml:"auto_increment,omitempty"`
+	Nullable      bool           `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Default       *string        `json:"default,omitempty" yaml:"default,omitempty"`
+	Extra         *string        `json:"extra,omitempty" yaml:"extra,omitempty"`
+	Comment       *string        `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Charset       *string        `json:"charset,omitempty" yaml:"charset,omitempty"`
+	Collation     *string        `json:"collation,omitempty" yaml:"collation,omitempty"`
+	Index         *SnapshotIndex `json:"index,omitempty" yaml:"index,omitempty"`
+}
+
+// SnapshotIndex is the index of the column in the snapshot.
+type SnapshotIndex struct {
+	Name   string `json:"name" yaml:"name"`
+	Unique bool   `json:"unique,omitempty" yaml:"unique,omitempty"`
+}
+
+// NewSnapshot returns a new snapshot of the given schemas.
+// The tables are sorted by name, and the columns keep the given order.
+// The table schemas of the tables that have no columns in schemas are ignored.
+func NewSnapshot(schemas []ColumnSchema, tableSchemas ...TableSchema) *Snapshot {
+	tableMap := map[string]*SnapshotTable{}
+	var s Snapshot
+	for _, schema := range schemas {
+		t := tableMap[schema.TableName()]
+		if t == nil {
+			t = &SnapshotTable{Name: schema.TableName()}
+			tableMap[t.Name] = t
+			s.Tables = append(s.Tables, t)
+		}
+		c := &SnapshotColumn{
+			Name:          schema.ColumnName(),
+			Type:          schema.ColumnType(),
+			DataType:      schema.DataType(),
+			PrimaryKey:    schema.IsPrimaryKey(),
+			AutoIncrement: schema.IsAutoIncrement(),
+			Nullable:      schema.IsNullable(),
+		}
+		if v, ok := schema.Default(); ok {
+			c.Default = &v
+		}
+		if v, ok := schema.Extra(); ok {
+			c.Extra = &v
+		}
+		if v, ok := schema.Comment(); ok {
+			c.Comment = &v
+		}
+		if cs, ok := schema.(CollationColumnSchema); ok {
+			if v, ok := cs.Charset(); ok {
+				c.Charset = &v
+			}
+			if v, ok := cs.Collation(); ok {
+				c.Collation = &v
+			}
+		}
+		if name, unique, ok := schema.Index(); ok {
+			c.Index = &SnapshotIndex{
+				Name:   name,
+				Unique: unique,
+			}
+		}
+		t.Columns = append(t.Columns, c)
+	}
+	for _, schema := range tableSchemas {
+		t := tableMap[schema.TableName()]
+		if t == nil {
+			continue
+		}
+		if v, ok := schema.Comment(); ok {
+			t.Comment = &v
+		}
+		t.Option, _ = schema.Option()
+	}
+	sort.Slice(s.Tables, func(i, j int) bool {
+		return s.Tables[i].Name < s.Tables[j].Name
+	})
+	return &s
+}
+
+// ReadSnapshotFile reads the snapshot from the file.
+// The file is decoded as YAML if the extension is .yaml or .yml, otherwise as JSON.
+func ReadSnapshotFile(filename string) (*Snapshot, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+	var s Snapshot
+	if isYAMLFile(filename) {
+		err = yaml.UnmarshalWithOptions(b, &s, yaml.DisallowDuplicateKey())
+	} else {
+		err = json.Unmarshal(b, &s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot file: %w", err)
+	}
+	return &s, nil
+}
+
+// WriteFile writes the snapshot to the file.
+// The file is encoded as YAML if the extension is .yaml or .yml, otherwise as JSON.
+func (s *Snapshot) WriteFile(filename string) error {
+	var b []byte
+	var err error
+	if isYAMLFile(filename) {
+		b, err = yaml.Marshal(s)
+	} else {
+		b, err = json.MarshalIndent(s, "", "  ")
+		b = append(b, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+func (s *Snapshot) defaultCollations() map[string]string {
+	if s == nil {
+		return nil
+	}
+	return s.DefaultCollations
+}
+
+// ColumnSchema returns the schemas of the given tables in the snapshot.
+// If no tables are given, it returns the schemas of all tables.
+func (s *Snapshot) ColumnSchema(tables ...string) []ColumnSchema {
+	if s == nil {
+		return nil
+	}
+	var schemas []ColumnSchema
+	for _, t := range s.findTables(tables) {
+		for _, c := range t.Columns {
+			schemas = append(schemas, &snapshotColumnSchema{
+				tableName: t.Name,
+				column:    c,
+			})
+		}
+	}
+	return schemas
+}
+
+// TableSchema returns the table schemas of the given tables in the snapshot.
+// If no tables are given, it returns the table schemas of all tables.
+func (s *Snapshot) TableSchema(tables ...string) []TableSchema {
+	if s == nil {
+		return nil
+	}
+	var schemas []TableSchema
+	for _, t := range s.findTables(tables) {
+		schemas = append(schemas, &snapshotTableSchema{table: t})
+	}
+	return schemas
+}
+
+// findTables returns the tables of the given names in the snapshot.
+// If no names are given, it returns all tables.
+func (s *Snapshot) findTables(names []string) []*SnapshotTable {
+	if len(names) == 0 {
+		return s.Tables
+	}
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	var tables []*SnapshotTable
+	for _, t := range s.Tables {
+		if _, ok := set[t.Name]; ok {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+func isYAMLFile(filename string) bool {
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+var _ TableSchema = &snapshotTableSchema{}
+
+type snapshotTableSchema struct {
+	table *SnapshotTable
+}
+
+func (s *snapshotTableSchema) TableName() string {
+	return s.table.Name
+}
+
+func (s *snapshotTableSchema) Comment() (string, bool) {
+	return stringPtrValue(s.table.Comment)
+}
+
+func (s *snapshotTableSchema) Option() (string, bool) {
+	return s.table.Option, s.table.Option != ""
+}
+
+var (
+	_ ColumnSchema          = &snapshotColumnSchema{}
+	_ CollationColumnSchema = &snapshotColumnSchema{}
+)
+
+type snapshotColumnSchema struct {
+	tableName string
+	column    *SnapshotColumn
+}
+
+func (s *snapshotColumnSchema) TableName() string {
+	return s.tableName
+}
+
+func (s *snapshotColumnSchema) ColumnName() string {
+	return s.column.Name
+}
+
+func (s *snapshotColumnSchema) ColumnType() string {
+	return s.column.Type
+}
+
+func (s *snapshotColumnSchema) DataType() string {
+	return s.column.DataType
+}
+
+func (s *snapshotColumnSchema) IsPrimaryKey() bool {
+	return s.column.PrimaryKey
+}
+
+func (s *snapshotColumnSchema) IsAutoIncrement() bool {
+	return s.column.AutoIncrement
+}
+
+func (s *snapshotColumnSchema) Index() (name string, unique bool, ok bool) {
+	if s.column.Index == nil {
+		return "", false, false
+	}
+	return s.column.Index.Name, s.column.Index.Unique, true
+}
+
+func (s *snapshotColumnSchema) Default() (string, bool) {
+	return stringPtrValue(s.column.Default)
+}
+
+func (s *snapshotColumnSchema) IsNullable() bool {
+	return s.column.Nullable
+}
+
+func (s *snapshotColumnSchema) Extra() (string, bool) {
+	return stringPtrValue(s.column.Extra)
+}
+
+func (s *snapshotColumnSchema) Comment() (string, bool) {
+	return stringPtrValue(s.column.Comment)
+}
+
+func (s *snapshotColumnSchema) Charset() (string, bool) {
+	return stringPtrValue(s.column.Charset)
+}
+
+func (s *snapshotColumnSchema) Collation() (string, bool) {
+	return stringPtrValue(s.column.Collation)
+}
+
+func stringPtrValue(s *string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	return *s, true
+}
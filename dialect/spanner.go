@@ -3,6 +3,8 @@ package dialect
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,6 +16,9 @@ import (
 	"google.golang.org/grpc"
 )
 
+// defaultSpannerDialTimeout is the timeout for establishing the connection if WithDialTimeout is not given.
+const defaultSpannerDialTimeout = 1 * time.Second
+
 var (
 	spannerColumnTypes = []*ColumnType{
 		{
@@ -68,6 +73,9 @@ type Spanner struct {
 	nullableTypeMap map[string]struct{}
 }
 
+// NewSpanner returns a new dialect for Cloud Spanner.
+// The database must be in the form of projects/PROJECT/instances/INSTANCE/databases/DATABASE.
+// If database is empty, the dialect is in offline mode. See Offliner.
 func NewSpanner(database string, opts ...Option) Dialect {
 	d := &Spanner{
 		database:        database,
@@ -92,6 +100,21 @@ func NewSpanner(database string, opts ...Option) Dialect {
 }
 
 func (s *Spanner) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	return s.ColumnSchemaContext(context.Background(), tables...)
+}
+
+func (s *Spanner) ColumnSchemaContext(ctx context.Context, tables ...string) ([]ColumnSchema, error) {
+	schemas, err := s.columnSchema(ctx, tables...)
+	if err != nil {
+		return nil, err
+	}
+	return s.opt.filterColumnSchemas(schemas), nil
+}
+
+func (s *Spanner) columnSchema(ctx context.Context, tables ...string) ([]ColumnSchema, error) {
+	if s.IsOffline() {
+		return s.opt.snapshot.ColumnSchema(tables...), nil
+	}
 	parts := []string{
 		"SELECT",
 		"  C.table_catalog,",
@@ -115,30 +138,46 @@ func (s *Spanner) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
 		// "  I.spanner_is_managed",
 		"FROM information_schema.columns AS c",
 		"LEFT OUTER JOIN information_schema.column_options AS co",
-		"  ON co.table_name = c.table_name AND co.column_name = c.column_name",
+		"  ON co.table_schema = c.table_schema AND co.table_name = c.table_name AND co.column_name = c.column_name",
 		"LEFT OUTER JOIN information_schema.index_columns AS ic",
-		"  ON ic.table_name = c.table_name AND ic.column_name = c.column_name",
+		"  ON ic.table_schema = c.table_schema AND ic.table_name = c.table_name AND ic.column_name = c.column_name",
 		"LEFT OUTER JOIN information_schema.indexes AS i",
-		"  ON i.table_name = ic.table_name AND i.index_name = ic.index_name",
-		"WHERE",
-		"  c.table_schema = ''",
+		"  ON i.table_schema = ic.table_schema AND i.table_name = ic.table_name AND i.index_name = ic.index_name",
 	}
 	params := map[string]interface{}{}
 	if len(tables) > 0 {
-		parts = append(parts, "AND c.table_name IN UNNEST(@tables)")
-		params["tables"] = tables
+		// The tables are grouped by the named schema. The unqualified tables belong to the default schema.
+		schemaTables := map[string][]string{}
+		for _, t := range tables {
+			schemaName, tableName := SplitTableName(t)
+			schemaTables[schemaName] = append(schemaTables[schemaName], tableName)
+		}
+		schemaNames := make([]string, 0, len(schemaTables))
+		for schemaName := range schemaTables {
+			schemaNames = append(schemaNames, schemaName)
+		}
+		sort.Strings(schemaNames)
+		conds := make([]string, len(schemaNames))
+		for i, schemaName := range schemaNames {
+			conds[i] = fmt.Sprintf("(c.table_schema = @schema%d AND c.table_name IN UNNEST(@tables%d))", i, i)
+			params[fmt.Sprintf("schema%d", i)] = schemaName
+			params[fmt.Sprintf("tables%d", i)] = schemaTables[schemaName]
+		}
+		parts = append(parts, "WHERE "+strings.Join(conds, " OR "))
+	} else {
+		parts = append(parts, "WHERE c.table_schema = ''")
 	}
-	parts = append(parts, "ORDER BY c.table_name, c.ordinal_position")
+	parts = append(parts, "ORDER BY c.table_schema, c.table_name, c.ordinal_position")
 	query := strings.Join(parts, "\n")
 	stmt := spanner.Statement{
 		SQL:    query,
 		Params: params,
 	}
-	client, err := s.client()
+	client, err := s.client(ctx)
 	if err != nil {
 		return nil, err
 	}
-	iter := client.Single().Query(context.Background(), stmt)
+	iter := client.Single().Query(ctx, stmt)
 	defer iter.Stop()
 	var schemas []ColumnSchema
 	for {
@@ -250,12 +289,12 @@ func (d *Spanner) CreateTableSQL(table Table) []string {
 	return []string{
 		fmt.Sprintf("CREATE TABLE %s (\n"+
 			"  %s\n"+
-			") PRIMARY KEY (%s)", d.Quote(table.Name), strings.Join(columns, ",\n  "), strings.Join(pks, ", ")),
+			") PRIMARY KEY (%s)", QuoteTable(d, table.Name), strings.Join(columns, ",\n  "), strings.Join(pks, ", ")),
 	}
 }
 
 func (d *Spanner) AddColumnSQL(field Field) []string {
-	tableName := d.Quote(field.Table)
+	tableName := QuoteTable(d, field.Table)
 	ret := []string{
 		fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", tableName, d.columnSQL(field)),
 	}
@@ -269,23 +308,23 @@ func (d *Spanner) AddColumnSQL(field Field) []string {
 }
 
 func (d *Spanner) DropColumnSQL(field Field) []string {
-	return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.Quote(field.Table), d.Quote(field.Name))}
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", QuoteTable(d, field.Table), d.Quote(field.Name))}
 }
 
 func (d *Spanner) ModifyColumnSQL(oldField, newField Field) []string {
 	ret := make([]string, 0, 2)
 	switch {
 	case (oldField.Nullable && !newField.Nullable) || (oldField.Type != newField.Type && !newField.Nullable):
-		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s NOT NULL", d.Quote(newField.Table), d.columnSQL(newField)))
+		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s NOT NULL", QuoteTable(d, newField.Table), d.columnSQL(newField)))
 	case (!oldField.Nullable && newField.Nullable) || (oldField.Type != newField.Type && newField.Nullable):
-		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", d.Quote(newField.Table), d.columnSQL(newField)))
+		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", QuoteTable(d, newField.Table), d.columnSQL(newField)))
 	}
 	switch {
 	case oldField.Extra == "" && newField.Extra != "":
-		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET OPTIONS (%s)", d.Quote(newField.Table), d.Quote(newField.Name), newField.Extra))
+		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET OPTIONS (%s)", QuoteTable(d, newField.Table), d.Quote(newField.Name), newField.Extra))
 	case oldField.Extra != "" && newField.Extra == "":
 		optName := strings.TrimSpace(oldField.Extra[:strings.IndexByte(oldField.Extra, '=')])
-		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET OPTIONS (%s = null)", d.Quote(newField.Table), d.Quote(newField.Name), optName))
+		ret = append(ret, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET OPTIONS (%s = null)", QuoteTable(d, newField.Table), d.Quote(newField.Name), optName))
 	}
 	return ret
 }
@@ -295,8 +334,8 @@ func (d *Spanner) CreateIndexSQL(index Index) []string {
 	for i, c := range index.Columns {
 		columns[i] = d.Quote(c)
 	}
-	indexName := d.Quote(index.Name)
-	tableName := d.Quote(index.Table)
+	indexName := d.quoteIndexName(index)
+	tableName := QuoteTable(d, index.Table)
 	column := strings.Join(columns, ",")
 	if index.Unique {
 		return []string{fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, column)}
@@ -305,7 +344,16 @@ func (d *Spanner) CreateIndexSQL(index Index) []string {
 }
 
 func (d *Spanner) DropIndexSQL(index Index) []string {
-	return []string{fmt.Sprintf("DROP INDEX %s", d.Quote(index.Name))}
+	return []string{fmt.Sprintf("DROP INDEX %s", d.quoteIndexName(index))}
+}
+
+// quoteIndexName returns the quoted index name that is qualified by the schema of the table,
+// because the index belongs to the schema in Cloud Spanner.
+func (d *Spanner) quoteIndexName(index Index) string {
+	if schema, _ := SplitTableName(index.Table); schema != "" {
+		return QuoteTable(d, schema+"."+index.Name)
+	}
+	return d.Quote(index.Name)
 }
 
 func (d *Spanner) columnSQL(f Field) string {
@@ -313,20 +361,39 @@ func (d *Spanner) columnSQL(f Field) string {
 }
 
 func (d *Spanner) Begin() (Transactioner, error) {
+	return d.BeginContext(context.Background())
+}
+
+// BeginContext starts a transaction.
+// Because DDL statements in Cloud Spanner are not transactional, the statements passed to Exec
+// are buffered and submitted in batches by Commit. The given context is used for the submission.
+func (d *Spanner) BeginContext(ctx context.Context) (Transactioner, error) {
+	if d.IsOffline() {
+		return nil, ErrOffline
+	}
 	return &spannerTransaction{
-		d: d,
+		ctx: ctx,
+		d:   d,
 	}, nil
 }
 
-func (d *Spanner) client() (*spanner.Client, error) {
+func (d *Spanner) IsTargetTable(name string) bool {
+	return d.opt.isTargetTable(name)
+}
+
+func (d *Spanner) IsIgnoredColumn(table, column string) bool {
+	return d.opt.isIgnoredColumn(table, column)
+}
+
+func (d *Spanner) IsOffline() bool {
+	return d.database == ""
+}
+
+func (d *Spanner) client(ctx context.Context) (*spanner.Client, error) {
 	if d.c != nil {
 		return d.c, nil
 	}
-	c, err := spanner.NewClient(context.Background(), d.database,
-		apioption.WithGRPCDialOption(grpc.WithBlock()),
-		apioption.WithGRPCDialOption(grpc.WithTimeout(1*time.Second)),
-		apioption.WithGRPCDialOption(grpc.WithDefaultCallOptions(grpc.WaitForReady(false))),
-	)
+	c, err := spanner.NewClient(ctx, d.database, d.clientOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -334,15 +401,11 @@ func (d *Spanner) client() (*spanner.Client, error) {
 	return c, nil
 }
 
-func (d *Spanner) adminClient() (*database.DatabaseAdminClient, error) {
+func (d *Spanner) adminClient(ctx context.Context) (*database.DatabaseAdminClient, error) {
 	if d.ac != nil {
 		return d.ac, nil
 	}
-	c, err := database.NewDatabaseAdminClient(context.Background(),
-		apioption.WithGRPCDialOption(grpc.WithBlock()),
-		apioption.WithGRPCDialOption(grpc.WithTimeout(1*time.Second)),
-		apioption.WithGRPCDialOption(grpc.WithDefaultCallOptions(grpc.WaitForReady(false))),
-	)
+	c, err := database.NewDatabaseAdminClient(ctx, d.clientOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -350,34 +413,125 @@ func (d *Spanner) adminClient() (*database.DatabaseAdminClient, error) {
 	return c, nil
 }
 
+func (d *Spanner) clientOptions() []apioption.ClientOption {
+	timeout := d.opt.dialTimeout
+	if timeout <= 0 {
+		timeout = defaultSpannerDialTimeout
+	}
+	opts := []apioption.ClientOption{
+		apioption.WithGRPCDialOption(grpc.WithBlock()),
+		apioption.WithGRPCDialOption(grpc.WithTimeout(timeout)),
+		apioption.WithGRPCDialOption(grpc.WithDefaultCallOptions(grpc.WaitForReady(false))),
+	}
+	emulatorHost := d.opt.emulatorHost
+	if emulatorHost == "" {
+		emulatorHost = os.Getenv("SPANNER_EMULATOR_HOST")
+	}
+	if emulatorHost != "" {
+		return append(opts,
+			apioption.WithEndpoint(emulatorHost),
+			apioption.WithGRPCDialOption(grpc.WithInsecure()),
+			apioption.WithoutAuthentication(),
+		)
+	}
+	if d.opt.endpoint != "" {
+		opts = append(opts, apioption.WithEndpoint(d.opt.endpoint))
+	}
+	if d.opt.credentialsFile != "" {
+		opts = append(opts, apioption.WithCredentialsFile(d.opt.credentialsFile))
+	}
+	return opts
+}
+
+var (
+	_ Offliner           = &Spanner{}
+	_ TableFilterer      = &Spanner{}
+	_ ColumnFilterer     = &Spanner{}
+	_ DDLTransactioner   = &spannerTransaction{}
+	_ BatchTransactioner = &spannerTransaction{}
+)
+
 type spannerTransaction struct {
-	d *Spanner
+	ctx   context.Context
+	d     *Spanner
+	stmts []string
 }
 
 func (s *spannerTransaction) Exec(sql string, args ...interface{}) error {
-	ctx := context.Background()
-	ac, err := s.d.adminClient()
-	if err != nil {
-		return err
-	}
-	op, err := ac.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
-		Database:   s.d.database,
-		Statements: []string{sql},
-	})
-	if err != nil {
+	return s.ExecContext(context.Background(), sql, args...)
+}
+
+// ExecContext buffers the statement to submit it by Commit.
+// The statements are submitted with the context given to BeginContext, so ctx is only checked here.
+func (s *spannerTransaction) ExecContext(ctx context.Context, sql string, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
-	return op.Wait(ctx)
+	s.stmts = append(s.stmts, sql)
+	return nil
 }
 
 func (s *spannerTransaction) Commit() error {
-	return s.close()
+	err := s.commit(s.ctx)
+	if cerr := s.close(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
 func (s *spannerTransaction) Rollback() error {
+	s.stmts = nil
 	return s.close()
 }
 
+// IsDDLTransactional returns false because DDL statements in the batch are applied one by one.
+func (s *spannerTransaction) IsDDLTransactional() bool {
+	return false
+}
+
+// IsBatched returns true because the statements are submitted in batches by Commit.
+func (s *spannerTransaction) IsBatched() bool {
+	return true
+}
+
+func (s *spannerTransaction) commit(ctx context.Context) error {
+	if len(s.stmts) == 0 {
+		return nil
+	}
+	ac, err := s.d.adminClient(ctx)
+	if err != nil {
+		return err
+	}
+	size := s.d.opt.ddlBatchSize
+	if size <= 0 {
+		size = len(s.stmts)
+	}
+	for start := 0; start < len(s.stmts); start += size {
+		end := start + size
+		if end > len(s.stmts) {
+			end = len(s.stmts)
+		}
+		op, err := ac.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+			Database:   s.d.database,
+			Statements: s.stmts[start:end],
+		})
+		if err != nil {
+			return &BatchError{Statements: s.stmts, Index: start, Err: err}
+		}
+		if err := op.Wait(ctx); err != nil {
+			// The commit timestamps in the metadata are reported for each statement that has succeeded.
+			// Therefore the number of them is the index of the failed statement in the batch.
+			var applied int
+			if md, merr := op.Metadata(); merr == nil && md != nil {
+				applied = len(md.CommitTimestamps)
+			}
+			return &BatchError{Statements: s.stmts, Index: start + applied, Err: err}
+		}
+	}
+	s.stmts = nil
+	return nil
+}
+
 func (s *spannerTransaction) close() error {
 	if s.d.c != nil {
 		s.d.c.Close()
@@ -424,6 +578,9 @@ type spannerColumnSchema struct {
 }
 
 func (s *spannerColumnSchema) TableName() string {
+	if s.tableSchema != "" {
+		return s.tableSchema + "." + s.tableName
+	}
 	return s.tableName
 }
 
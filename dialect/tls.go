@@ -0,0 +1,148 @@
+package dialect
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// The SSL modes of MySQL/MariaDB. They are the same as --ssl-mode of the mysql client.
+const (
+	SSLModeDisabled       = "DISABLED"
+	SSLModePreferred      = "PREFERRED"
+	SSLModeRequired       = "REQUIRED"
+	SSLModeVerifyCA       = "VERIFY_CA"
+	SSLModeVerifyIdentity = "VERIFY_IDENTITY"
+)
+
+var tlsConfigID int64
+
+// MySQLTLS is the TLS settings of the connection to MySQL/MariaDB.
+type MySQLTLS struct {
+	// Mode is one of SSLMode* constants (case-insensitive).
+	// If Mode is empty, it is SSLModeVerifyCA if CA is given, SSLModeRequired if Cert is given,
+	// otherwise the TLS setting of the config is used as it is.
+	Mode string
+
+	// CA is the path to the PEM file of the certificate authorities to verify the server certificate.
+	CA string
+
+	// Cert and Key are the paths to the PEM files of the client certificate and its private key.
+	Cert string
+	Key  string
+}
+
+// OpenMySQL opens the MySQL/MariaDB database by config of Go-MySQL-Driver with the TLS settings.
+// If tlsOpt is nil, it's the same as sql.Open with config.FormatDSN().
+func OpenMySQL(config *mysql.Config, tlsOpt *MySQLTLS) (*sql.DB, error) {
+	config = config.Clone()
+	if tlsOpt != nil {
+		name, err := tlsOpt.register()
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			config.TLSConfig = name
+		}
+	}
+	connector, err := mysql.NewConnector(config)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// register registers the tls.Config with Go-MySQL-Driver, and returns the name of it.
+// It returns an empty name if the TLS setting is not changed.
+func (t *MySQLTLS) register() (string, error) {
+	mode := strings.ToUpper(t.Mode)
+	if mode == "" {
+		switch {
+		case t.CA != "":
+			mode = SSLModeVerifyCA
+		case t.Cert != "" || t.Key != "":
+			mode = SSLModeRequired
+		default:
+			return "", nil
+		}
+	}
+	switch mode {
+	case SSLModeDisabled:
+		return "false", nil
+	case SSLModePreferred:
+		if t.Cert != "" || t.Key != "" {
+			return "", fmt.Errorf("dialect: client certificate cannot be used with SSL mode %s", mode)
+		}
+		return "preferred", nil
+	case SSLModeRequired, SSLModeVerifyCA, SSLModeVerifyIdentity:
+		// do nothing.
+	default:
+		return "", fmt.Errorf("dialect: unknown SSL mode: %s", t.Mode)
+	}
+	config := &tls.Config{}
+	if t.Cert != "" || t.Key != "" {
+		if t.Cert == "" || t.Key == "" {
+			return "", fmt.Errorf("dialect: both client certificate and key must be specified")
+		}
+		cert, err := tls.LoadX509KeyPair(t.Cert, t.Key)
+		if err != nil {
+			return "", fmt.Errorf("dialect: failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if t.CA != "" {
+		b, err := ioutil.ReadFile(t.CA)
+		if err != nil {
+			return "", fmt.Errorf("dialect: failed to read CA certificate: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(b) {
+			return "", fmt.Errorf("dialect: no certificates found in %s", t.CA)
+		}
+	}
+	switch mode {
+	case SSLModeRequired:
+		config.InsecureSkipVerify = true
+	case SSLModeVerifyCA:
+		// Verify the certificate chain but not the host name.
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = verifyCertificateChain(config.RootCAs)
+	}
+	name := "migu-" + strconv.FormatInt(atomic.AddInt64(&tlsConfigID, 1), 10)
+	if err := mysql.RegisterTLSConfig(name, config); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func verifyCertificateChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("dialect: server certificate is not given")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
@@ -0,0 +1,49 @@
+package dialect
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMySQLTLSRegister(t *testing.T) {
+	for _, v := range []struct {
+		name   string
+		tls    MySQLTLS
+		expect string
+		err    string
+	}{
+		{"no settings", MySQLTLS{}, "", ""},
+		{"disabled", MySQLTLS{Mode: SSLModeDisabled}, "false", ""},
+		{"preferred", MySQLTLS{Mode: "preferred"}, "preferred", ""},
+		{"preferred with certificate", MySQLTLS{Mode: SSLModePreferred, Cert: "cert.pem", Key: "key.pem"}, "", "cannot be used with SSL mode"},
+		{"required", MySQLTLS{Mode: SSLModeRequired}, "migu-", ""},
+		{"verify identity", MySQLTLS{Mode: "Verify_Identity"}, "migu-", ""},
+		{"unknown mode", MySQLTLS{Mode: "ALWAYS"}, "", "unknown SSL mode"},
+		{"certificate without key", MySQLTLS{Mode: SSLModeRequired, Cert: "cert.pem"}, "", "both client certificate and key"},
+		{"CA implies VERIFY_CA", MySQLTLS{CA: "nonexistent-ca.pem"}, "", "failed to read CA certificate"},
+		{"certificate implies REQUIRED", MySQLTLS{Cert: "cert.pem"}, "", "both client certificate and key"},
+	} {
+		v := v
+		t.Run(v.name, func(t *testing.T) {
+			actual, err := v.tls.register()
+			if v.err != "" {
+				if err == nil || !strings.Contains(err.Error(), v.err) {
+					t.Errorf("register() => %q, %v; want error %q", actual, err, v.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.expect == "migu-" {
+				if !strings.HasPrefix(actual, v.expect) {
+					t.Errorf("register() => %q; want the registered name", actual)
+				}
+				return
+			}
+			if actual != v.expect {
+				t.Errorf("register() => %q; want %q", actual, v.expect)
+			}
+		})
+	}
+}
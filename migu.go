@@ -2,6 +2,7 @@ package migu
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -33,29 +34,106 @@ const (
 // The type of the argument for the src parameter must be string, []byte, or
 // io.Reader. If src == nil, Sync parses the file specified by filename.
 //
+// If filename has the .sql extension, the schema is read from CREATE TABLE
+// and CREATE INDEX statements of MySQL or Cloud Spanner instead of Go's struct.
+// If filename has the .yaml, .yml or .json extension, the schema is read from
+// the declarative schema. See Schema.
+//
 // All query for synchronization will be performed within the transaction if
 // storage engine supports the transaction. (e.g. MySQL's MyISAM engine does
 // NOT support the transaction)
+// Note that DDL statements of MySQL and Cloud Spanner are committed implicitly,
+// so they cannot be rolled back. See Apply for the error in that case.
 func Sync(d dialect.Dialect, filename string, src interface{}) error {
-	sqls, err := Diff(d, filename, src)
+	return SyncContext(context.Background(), d, filename, src)
+}
+
+// SyncContext is like Sync but with the context.
+// The context is used for both the computation of differences and the execution of queries.
+func SyncContext(ctx context.Context, d dialect.Dialect, filename string, src interface{}) error {
+	plan, err := MakePlan(ctx, d, filename, src)
 	if err != nil {
 		return err
 	}
-	tx, err := d.Begin()
+	return Apply(ctx, d, plan, nil)
+}
+
+// Diff returns SQLs for schema synchronous between database and Go's struct.
+func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error) {
+	return DiffContext(context.Background(), d, filename, src)
+}
+
+// DiffContext is like Diff but with the context.
+func DiffContext(ctx context.Context, d dialect.Dialect, filename string, src interface{}) ([]string, error) {
+	plan, err := MakePlan(ctx, d, filename, src)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, sql := range sqls {
-		if err := tx.Exec(sql); err != nil {
-			tx.Rollback()
-			return err
-		}
+	return plan.SQLs(), nil
+}
+
+// MakePlan returns the plan for schema synchronous between database and Go's struct.
+// The filename and src parameters are the same as Sync.
+func MakePlan(ctx context.Context, d dialect.Dialect, filename string, src interface{}) (*Plan, error) {
+	structMap, err := makeSchemaTableMap(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(structMap))
+	for name := range structMap {
+		names = append(names, name)
+	}
+	tableMap, err := makeDatabaseTableMap(ctx, d, names...)
+	if err != nil {
+		return nil, err
 	}
-	return tx.Commit()
+	return diffTables(d, tableMap, structMap), nil
 }
 
-// Diff returns SQLs for schema synchronous between database and Go's struct.
-func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error) {
+// MakeDatabasePlan returns the plan to make the schema of the database from
+// the same as the schema of the database to.
+// The SQLs of the plan are generated by the dialect of from.
+func MakeDatabasePlan(ctx context.Context, from, to dialect.Dialect) (*Plan, error) {
+	oldTableMap, err := makeDatabaseTableMap(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	newTableMap, err := makeDatabaseTableMap(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	return diffTables(from, oldTableMap, newTableMap), nil
+}
+
+// MakeSourcePlan returns the plan to make the schema defined by the old Go's
+// structs from the same as the schema defined by the new Go's structs.
+// It doesn't connect to any database, so d can be a dialect without connection.
+// The filename and src parameters are the same as Sync.
+func MakeSourcePlan(d dialect.Dialect, oldFilename string, oldSrc interface{}, newFilename string, newSrc interface{}) (*Plan, error) {
+	oldTableMap, err := makeSchemaTableMap(d, oldFilename, oldSrc)
+	if err != nil {
+		return nil, err
+	}
+	newTableMap, err := makeSchemaTableMap(d, newFilename, newSrc)
+	if err != nil {
+		return nil, err
+	}
+	return diffTables(d, oldTableMap, newTableMap), nil
+}
+
+// makeSchemaTableMap returns the tables which are defined by the schema source.
+func makeSchemaTableMap(d dialect.Dialect, filename string, src interface{}) (map[string]*table, error) {
+	if isDDLFile(filename) {
+		return makeDDLTableMap(d, filename, src)
+	}
+	if isSchemaFile(filename) {
+		return makeSchemaFileTableMap(d, filename, src)
+	}
+	return makeStructTableMap(d, filename, src)
+}
+
+// makeStructTableMap returns the tables which are defined by Go's structs.
+func makeStructTableMap(d dialect.Dialect, filename string, src interface{}) (map[string]*table, error) {
 	var filenames []string
 	structASTMap := make(map[string]*structAST)
 	if src == nil {
@@ -95,61 +173,131 @@ func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error)
 			}
 			if structMap[name] == nil {
 				structMap[name] = &table{
-					Option: structAST.Annotation.Option,
+					Name:          name,
+					Option:        structAST.Annotation.Option,
+					Comment:       structAST.Comment,
+					IgnoreColumns: structAST.Annotation.IgnoreColumns,
+					Unmanaged:     structAST.Annotation.Unmanaged,
 				}
 			}
 			structMap[name].Fields = append(structMap[name].Fields, f)
 		}
 	}
-	names := make([]string, 0, len(structMap))
-	for name := range structMap {
-		names = append(names, name)
+	return structMap, nil
+}
+
+// makeDatabaseTableMap returns the tables on the database.
+// If no tables are given, it returns all tables.
+func makeDatabaseTableMap(ctx context.Context, d dialect.Dialect, tables ...string) (map[string]*table, error) {
+	schemaMap, err := getTableMap(ctx, d, tables...)
+	if err != nil {
+		return nil, err
 	}
-	tableMap, err := getTableMap(d, names...)
+	tableMap := make(map[string]*table, len(schemaMap))
+	for name, columns := range schemaMap {
+		fields, err := makeFields(d, name, columns)
+		if err != nil {
+			return nil, err
+		}
+		tableMap[name] = &table{
+			Name:   name,
+			Fields: fields,
+		}
+	}
+	tableSchemaMap, err := getTableSchemaMap(ctx, d, tables...)
 	if err != nil {
 		return nil, err
 	}
+	for name, schema := range tableSchemaMap {
+		if tbl, ok := tableMap[name]; ok {
+			tbl.Comment, _ = schema.Comment()
+			tbl.Option, _ = schema.Option()
+		}
+	}
+	return tableMap, nil
+}
+
+// diffTables returns the plan to make the schema from oldTableMap to newTableMap.
+// The tables and columns that are not managed by Migu are ignored. See filterTables.
+func diffTables(d dialect.Dialect, oldTableMap, newTableMap map[string]*table) *Plan {
+	oldTableMap, newTableMap = filterTables(d, oldTableMap, newTableMap)
+	names := make([]string, 0, len(newTableMap))
+	for name := range newTableMap {
+		names = append(names, name)
+	}
 	sort.Strings(names)
-	var migrations []string
+	plan := &Plan{d: d}
 	droppedColumn := map[string]struct{}{}
 	for _, name := range names {
-		tbl := structMap[name]
+		tbl := newTableMap[name]
 		var oldFields []*field
-		if columns, ok := tableMap[name]; ok {
-			for _, c := range columns {
-				oldFieldAST, err := fieldAST(d, c)
-				if err != nil {
-					return nil, err
-				}
-				f, err := newField(d, name, fmt.Sprint(oldFieldAST.Type), oldFieldAST)
-				if err != nil {
-					return nil, err
+		if oldTbl, ok := oldTableMap[name]; ok {
+			oldFields = oldTbl.Fields
+			if cd, ok := d.(dialect.CollationNormalizer); ok {
+				oldFields = normalizeCollations(cd, oldFields, oldTbl.Option, tbl.Option)
+				t := *tbl
+				t.Fields = normalizeCollations(cd, tbl.Fields, oldTbl.Option, tbl.Option)
+				tbl = &t
+			}
+			// converted reports whether the character set of the table is converted.
+			// The conversion also converts the columns which have the explicit
+			// character set or collation, so they must be modified back after that.
+			var converted bool
+			if td, ok := d.(dialect.TableModifier); ok {
+				converted = td.IsTableDataConverted(oldTbl.ToTable(), tbl.ToTable())
+				if sqls := td.ModifyTableSQL(oldTbl.ToTable(), tbl.ToTable()); len(sqls) > 0 {
+					plan.add(&Change{
+						Kind:     ModifyTable,
+						Table:    name,
+						SQLs:     sqls,
+						oldTable: oldTbl,
+						newTable: tbl,
+					})
 				}
-				oldFields = append(oldFields, f)
 			}
 			fields := makeAlterTableFields(oldFields, tbl.Fields)
+			if converted {
+				fields = appendConvertedFields(fields, oldFields, tbl.Fields)
+			}
 			for _, f := range fields {
 				switch {
 				case f.IsAdded():
-					migrations = append(migrations, d.AddColumnSQL(f.new.ToField())...)
+					plan.add(&Change{
+						Kind:     AddColumn,
+						Table:    name,
+						Column:   f.new.Column,
+						SQLs:     d.AddColumnSQL(f.new.ToField()),
+						newField: f.new,
+					})
 				case f.IsDropped():
-					migrations = append(migrations, d.DropColumnSQL(f.old.ToField())...)
+					plan.add(&Change{
+						Kind:     DropColumn,
+						Table:    name,
+						Column:   f.old.Column,
+						SQLs:     d.DropColumnSQL(f.old.ToField()),
+						oldField: f.old,
+					})
 				case f.IsModified():
-					migrations = append(migrations, d.ModifyColumnSQL(f.old.ToField(), f.new.ToField())...)
+					plan.add(&Change{
+						Kind:     ModifyColumn,
+						Table:    name,
+						Column:   f.new.Column,
+						SQLs:     d.ModifyColumnSQL(f.old.ToField(), f.new.ToField()),
+						oldField: f.old,
+						newField: f.new,
+					})
 				}
 			}
-			if d, ok := d.(dialect.PrimaryKeyModifier); ok {
+			if pd, ok := d.(dialect.PrimaryKeyModifier); ok {
 				oldPks, newPks := makePrimaryKeyColumns(oldFields, tbl.Fields)
 				if len(oldPks) > 0 || len(newPks) > 0 {
-					oldPrimaryKeyFields := make([]dialect.Field, len(oldPks))
-					for i, pk := range oldPks {
-						oldPrimaryKeyFields[i] = pk.ToField()
-					}
-					newPrimaryKeyFields := make([]dialect.Field, len(newPks))
-					for i, pk := range newPks {
-						newPrimaryKeyFields[i] = pk.ToField()
-					}
-					migrations = append(migrations, d.ModifyPrimaryKeySQL(oldPrimaryKeyFields, newPrimaryKeyFields)...)
+					plan.add(&Change{
+						Kind:   ModifyPrimaryKey,
+						Table:  name,
+						SQLs:   pd.ModifyPrimaryKeySQL(toFields(oldPks), toFields(newPks)),
+						oldPks: oldPks,
+						newPks: newPks,
+					})
 				}
 			}
 			for _, f := range fields {
@@ -157,41 +305,200 @@ func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error)
 					droppedColumn[f.old.Column] = struct{}{}
 				}
 			}
+			attachDroppedIndexes(plan, name, oldFields, tbl.Fields)
 		} else {
-			fields := make([]dialect.Field, len(tbl.Fields))
-			for i, f := range tbl.Fields {
-				fields[i] = f.ToField()
-			}
-			_, newPks := makePrimaryKeyColumns(oldFields, tbl.Fields)
-			pkColumns := make([]string, len(newPks))
-			for i, pk := range newPks {
-				pkColumns[i] = pk.ToField().Name
-			}
-			migrations = append(migrations, d.CreateTableSQL(dialect.Table{
-				Name:        name,
-				Fields:      fields,
-				PrimaryKeys: pkColumns,
-				Option:      tbl.Option,
-			})...)
+			plan.add(&Change{
+				Kind:     CreateTable,
+				Table:    name,
+				SQLs:     d.CreateTableSQL(tbl.ToTable()),
+				newTable: tbl,
+			})
 		}
 		addIndexes, dropIndexes := makeIndexes(oldFields, tbl.Fields)
 		for _, index := range dropIndexes {
 			// If the column which has the index will be deleted, Migu will not delete the index related to the column
 			// because the index will be deleted when the column which related to the index will be deleted.
 			if _, ok := droppedColumn[index.Columns[0]]; !ok {
-				migrations = append(migrations, d.DropIndexSQL(index.ToIndex())...)
+				plan.add(&Change{
+					Kind:  DropIndex,
+					Table: name,
+					Index: index.Name,
+					SQLs:  d.DropIndexSQL(index.ToIndex()),
+					index: index,
+				})
 			}
 		}
 		for _, index := range addIndexes {
-			migrations = append(migrations, d.CreateIndexSQL(index.ToIndex())...)
+			plan.add(&Change{
+				Kind:  CreateIndex,
+				Table: name,
+				Index: index.Name,
+				SQLs:  d.CreateIndexSQL(index.ToIndex()),
+				index: index,
+			})
 		}
-		delete(structMap, name)
-		delete(tableMap, name)
 	}
-	for name := range tableMap {
-		migrations = append(migrations, fmt.Sprintf(`DROP TABLE %s`, d.Quote(name)))
+	dropNames := make([]string, 0, len(oldTableMap))
+	for name := range oldTableMap {
+		if _, ok := newTableMap[name]; !ok {
+			dropNames = append(dropNames, name)
+		}
+	}
+	sort.Strings(dropNames)
+	for _, name := range dropNames {
+		plan.add(&Change{
+			Kind:     DropTable,
+			Table:    name,
+			SQLs:     []string{fmt.Sprintf(`DROP TABLE %s`, dialect.QuoteTable(d, name))},
+			oldTable: oldTableMap[name],
+		})
+	}
+	return plan
+}
+
+// appendConvertedFields appends the fields which have the explicit character set
+// or collation to fields as modified even if they are not changed, because
+// the conversion of the table also converts them into the character set of the table.
+func appendConvertedFields(fields []modifiedField, oldFields, newFields []*field) []modifiedField {
+	modified := make(map[*field]struct{}, len(fields))
+	for _, f := range fields {
+		if f.new != nil {
+			modified[f.new] = struct{}{}
+		}
+	}
+	for _, f := range newFields {
+		if _, ok := modified[f]; ok || (f.Charset == "" && f.Collation == "") {
+			continue
+		}
+		for _, oldF := range oldFields {
+			if oldF.Column == f.Column || oldF.Name == f.Name {
+				fields = append(fields, modifiedField{
+					old: oldF,
+					new: f,
+				})
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// attachDroppedIndexes attaches the indexes that are dropped together with the
+// dropped columns of the table to the DropColumn changes in order to restore
+// them by Reverse. The index is attached to the change that drops the first of
+// its columns so that the index is restored after all its columns are added back.
+func attachDroppedIndexes(plan *Plan, tableName string, oldFields, newFields []*field) {
+	newColumns := make(map[string]struct{}, len(newFields))
+	for _, f := range newFields {
+		newColumns[f.Column] = struct{}{}
+	}
+	oldIndexes, _ := makeIndexes(nil, oldFields)
+	for _, index := range oldIndexes {
+		dropped := true
+		for _, column := range index.Columns {
+			if _, exists := newColumns[column]; exists {
+				dropped = false
+				break
+			}
+		}
+		if !dropped {
+			continue
+		}
+		for _, c := range plan.Changes {
+			if c.Kind == DropColumn && c.Table == tableName && inStrings(index.Columns, c.Column) {
+				c.indexes = append(c.indexes, index)
+				break
+			}
+		}
+	}
+}
+
+// filterTables returns the tables and columns that are managed by Migu.
+// The following are excluded from both oldTableMap and newTableMap:
+//
+//   - the tables that are not targets of the dialect. See dialect.TableFilterer.
+//   - the tables that are annotated with managed:"false" on either side.
+//   - the columns that are annotated with ignore_columns on either side,
+//     or are ignored by the dialect. See dialect.ColumnFilterer.
+func filterTables(d dialect.Dialect, oldTableMap, newTableMap map[string]*table) (map[string]*table, map[string]*table) {
+	isTarget := func(name string) bool {
+		if f, ok := d.(dialect.TableFilterer); ok && !f.IsTargetTable(name) {
+			return false
+		}
+		for _, m := range []map[string]*table{oldTableMap, newTableMap} {
+			if tbl, ok := m[name]; ok && tbl.Unmanaged {
+				return false
+			}
+		}
+		return true
+	}
+	isIgnoredColumn := func(name, column string) bool {
+		if f, ok := d.(dialect.ColumnFilterer); ok && f.IsIgnoredColumn(name, column) {
+			return true
+		}
+		for _, m := range []map[string]*table{oldTableMap, newTableMap} {
+			if tbl, ok := m[name]; ok && inStrings(tbl.IgnoreColumns, column) {
+				return true
+			}
+		}
+		return false
+	}
+	filter := func(tableMap map[string]*table) map[string]*table {
+		filtered := make(map[string]*table, len(tableMap))
+		for name, tbl := range tableMap {
+			if !isTarget(name) {
+				continue
+			}
+			fields := make([]*field, 0, len(tbl.Fields))
+			for _, f := range tbl.Fields {
+				if !isIgnoredColumn(name, f.Column) {
+					fields = append(fields, f)
+				}
+			}
+			if len(fields) != len(tbl.Fields) {
+				t := *tbl
+				t.Fields = fields
+				tbl = &t
+			}
+			filtered[name] = tbl
+		}
+		return filtered
 	}
-	return migrations, nil
+	return filter(oldTableMap), filter(newTableMap)
+}
+
+// normalizeCollations returns the fields whose character set and collation are normalized by d.
+// The fields are copied if they are changed.
+func normalizeCollations(d dialect.CollationNormalizer, fields []*field, tableOptions ...string) []*field {
+	normalized := make([]*field, len(fields))
+	for i, f := range fields {
+		charset, collation := d.NormalizeCollation(f.Charset, f.Collation, tableOptions...)
+		if charset != f.Charset || collation != f.Collation {
+			nf := *f
+			nf.Charset, nf.Collation = charset, collation
+			f = &nf
+		}
+		normalized[i] = f
+	}
+	return normalized
+}
+
+// makeFields converts the column schemas of the table into the fields.
+func makeFields(d dialect.Dialect, tableName string, columns []dialect.ColumnSchema) ([]*field, error) {
+	fields := make([]*field, 0, len(columns))
+	for _, c := range columns {
+		fAST, err := fieldAST(d, c)
+		if err != nil {
+			return nil, err
+		}
+		f, err := newField(d, tableName, fmt.Sprint(fAST.Type), fAST)
+		if err != nil {
+			return nil, err
+		}
+		f.Column = c.ColumnName()
+		fields = append(fields, f)
+	}
+	return fields, nil
 }
 
 func collectFiles(path string) ([]string, error) {
@@ -226,8 +533,31 @@ func collectFiles(path string) ([]string, error) {
 }
 
 type table struct {
-	Fields []*field
-	Option string
+	Name    string
+	Fields  []*field
+	Option  string
+	Comment string
+
+	// IgnoreColumns is the list of the columns that are managed by others.
+	IgnoreColumns []string
+
+	// Unmanaged reports whether the table is for documentation only.
+	Unmanaged bool
+}
+
+func (t *table) ToTable() dialect.Table {
+	_, pks := makePrimaryKeyColumns(nil, t.Fields)
+	pkColumns := make([]string, len(pks))
+	for i, pk := range pks {
+		pkColumns[i] = pk.Column
+	}
+	return dialect.Table{
+		Name:        t.Name,
+		Fields:      toFields(t.Fields),
+		PrimaryKeys: pkColumns,
+		Option:      t.Option,
+		Comment:     t.Comment,
+	}
 }
 
 type index struct {
@@ -261,6 +591,8 @@ type field struct {
 	Default       string
 	Extra         string
 	Nullable      bool
+	Charset       string
+	Collation     string
 }
 
 func newField(d dialect.Dialect, tableName string, typeName string, f *ast.Field) (*field, error) {
@@ -310,7 +642,7 @@ func (f *field) Indexes() []string {
 	indexes := make([]string, 0, len(f.RawIndexes))
 	for _, index := range f.RawIndexes {
 		if index == "" {
-			index = stringutil.ToSnakeCase(f.Table) + "_" + f.Column
+			index = defaultIndexName(f.Table, f.Column)
 		}
 		indexes = append(indexes, index)
 	}
@@ -321,13 +653,20 @@ func (f *field) UniqueIndexes() []string {
 	uniques := make([]string, 0, len(f.RawUniques))
 	for _, u := range f.RawUniques {
 		if u == "" {
-			u = stringutil.ToSnakeCase(f.Table) + "_" + f.Column
+			u = defaultIndexName(f.Table, f.Column)
 		}
 		uniques = append(uniques, u)
 	}
 	return uniques
 }
 
+// defaultIndexName returns the name of the index that is not named explicitly.
+// The schema of the table is not a part of the name.
+func defaultIndexName(tableName, column string) string {
+	_, name := dialect.SplitTableName(tableName)
+	return stringutil.ToSnakeCase(name) + "_" + column
+}
+
 func (f *field) IsDifferent(another *field) bool {
 	if f == nil && another == nil {
 		return false
@@ -339,7 +678,9 @@ func (f *field) IsDifferent(another *field) bool {
 		f.Column != another.Column ||
 		f.Extra != another.Extra ||
 		f.Comment != another.Comment ||
-		f.AutoIncrement != another.AutoIncrement
+		f.AutoIncrement != another.AutoIncrement ||
+		f.Charset != another.Charset ||
+		f.Collation != another.Collation
 }
 
 func (f *field) IsEmbedded() bool {
@@ -356,7 +697,17 @@ func (f *field) ToField() dialect.Field {
 		Default:       f.Default,
 		Extra:         f.Extra,
 		Nullable:      f.Nullable,
+		Charset:       f.Charset,
+		Collation:     f.Collation,
+	}
+}
+
+func toFields(fields []*field) []dialect.Field {
+	ret := make([]dialect.Field, len(fields))
+	for i, f := range fields {
+		ret[i] = f.ToField()
 	}
+	return ret
 }
 
 func makePrimaryKeyColumns(oldFields, newFields []*field) (oldPks, newPks []*field) {
@@ -514,9 +865,54 @@ func makeAlterTableFields(oldFields, newFields []*field) (fields []modifiedField
 	return fields
 }
 
+// FprintSQL generates CREATE TABLE and CREATE INDEX statements from database schema and writes to output.
+// The output can be used as the schema source. See Sync.
+func FprintSQL(output io.Writer, d dialect.Dialect) error {
+	return FprintSQLContext(context.Background(), output, d)
+}
+
+// FprintSQLContext is like FprintSQL but with the context.
+func FprintSQLContext(ctx context.Context, output io.Writer, d dialect.Dialect) error {
+	tableMap, err := makeDatabaseTableMap(ctx, d)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(tableMap))
+	for name := range tableMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		tbl := tableMap[name]
+		sqls := d.CreateTableSQL(tbl.ToTable())
+		addIndexes, _ := makeIndexes(nil, tbl.Fields)
+		for _, index := range addIndexes {
+			sqls = append(sqls, d.CreateIndexSQL(index.ToIndex())...)
+		}
+		if i > 0 {
+			fmt.Fprintln(output)
+		}
+		for _, sql := range sqls {
+			if _, err := fmt.Fprintf(output, "%s;\n", sql); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Fprint generates Go's structs from database schema and writes to output.
 func Fprint(output io.Writer, d dialect.Dialect) error {
-	tableMap, err := getTableMap(d)
+	return FprintContext(context.Background(), output, d)
+}
+
+// FprintContext is like Fprint but with the context.
+func FprintContext(ctx context.Context, output io.Writer, d dialect.Dialect) error {
+	tableMap, err := getTableMap(ctx, d)
+	if err != nil {
+		return err
+	}
+	tableSchemaMap, err := getTableSchemaMap(ctx, d)
 	if err != nil {
 		return err
 	}
@@ -548,6 +944,13 @@ func Fprint(output io.Writer, d dialect.Dialect) error {
 		if err != nil {
 			return err
 		}
+		if schema, ok := tableSchemaMap[name]; ok {
+			if comment, ok := schema.Comment(); ok {
+				for _, line := range strings.Split(comment, "\n") {
+					fmt.Fprintln(output, strings.TrimSpace(commentPrefix+" "+line))
+				}
+			}
+		}
 		fmt.Fprintln(output, commentPrefix+marker)
 		if err := fprintln(output, s); err != nil {
 			return err
@@ -566,11 +969,13 @@ const (
 	tagType          = "type"
 	tagNull          = "null"
 	tagExtra         = "extra"
+	tagCharset       = "charset"
+	tagCollate       = "collate"
 	tagIgnore        = "-"
 )
 
-func getTableMap(d dialect.Dialect, tables ...string) (map[string][]dialect.ColumnSchema, error) {
-	schemas, err := d.ColumnSchema(tables...)
+func getTableMap(ctx context.Context, d dialect.Dialect, tables ...string) (map[string][]dialect.ColumnSchema, error) {
+	schemas, err := d.ColumnSchemaContext(ctx, tables...)
 	if err != nil {
 		return nil, err
 	}
@@ -581,6 +986,24 @@ func getTableMap(d dialect.Dialect, tables ...string) (map[string][]dialect.Colu
 	return tableMap, nil
 }
 
+// getTableSchemaMap returns the table schemas by the table name.
+// It returns nil if the dialect cannot read the table schemas.
+func getTableSchemaMap(ctx context.Context, d dialect.Dialect, tables ...string) (map[string]dialect.TableSchema, error) {
+	r, ok := d.(dialect.TableSchemaReader)
+	if !ok {
+		return nil, nil
+	}
+	schemas, err := r.TableSchemaContext(ctx, tables...)
+	if err != nil {
+		return nil, err
+	}
+	tableSchemaMap := make(map[string]dialect.TableSchema, len(schemas))
+	for _, s := range schemas {
+		tableSchemaMap[s.TableName()] = s
+	}
+	return tableSchemaMap, nil
+}
+
 func fprintln(output io.Writer, decl ast.Decl) error {
 	if err := format.Node(output, token.NewFileSet(), decl); err != nil {
 		return err
@@ -592,6 +1015,9 @@ func fprintln(output io.Writer, decl ast.Decl) error {
 type structAST struct {
 	StructType *ast.StructType
 	Annotation *annotation
+
+	// Comment is the table comment given by the annotation or the doc comment.
+	Comment string
 }
 
 func makeStructASTMap(filename string, src interface{}) (map[string]*structAST, error) {
@@ -625,12 +1051,19 @@ func makeStructASTMap(filename string, src interface{}) (map[string]*structAST,
 			st := &structAST{
 				StructType: t,
 				Annotation: annotation,
+				Comment:    docComment(d.Doc),
 			}
-			if annotation.Table != "" {
-				structASTMap[annotation.Table] = st
-			} else {
-				structASTMap[stringutil.ToSnakeCase(s.Name.Name)] = st
+			if annotation.Comment != nil {
+				st.Comment = *annotation.Comment
 			}
+			name := annotation.Table
+			if name == "" {
+				name = stringutil.ToSnakeCase(s.Name.Name)
+			}
+			if annotation.Schema != "" {
+				name = annotation.Schema + "." + name
+			}
+			structASTMap[name] = st
 		}
 	}
 	return structASTMap, nil
@@ -754,6 +1187,22 @@ func parseStructTag(d dialect.Dialect, f *field, tag reflect.StructTag) error {
 				return fmt.Errorf("`extra` tag must specify the parameter")
 			}
 			f.Extra = optval[1]
+		case tagCharset:
+			if len(optval) < 2 {
+				return fmt.Errorf("`charset` tag must specify the parameter")
+			}
+			if _, ok := d.(dialect.CollationNormalizer); !ok {
+				return fmt.Errorf("`charset` tag is not supported by the dialect")
+			}
+			f.Charset = optval[1]
+		case tagCollate:
+			if len(optval) < 2 {
+				return fmt.Errorf("`collate` tag must specify the parameter")
+			}
+			if _, ok := d.(dialect.CollationNormalizer); !ok {
+				return fmt.Errorf("`collate` tag is not supported by the dialect")
+			}
+			f.Collation = optval[1]
 		default:
 			return fmt.Errorf("unknown option: `%s'", opt)
 		}
@@ -815,6 +1264,14 @@ func fieldAST(d dialect.Dialect, schema dialect.ColumnSchema) (*ast.Field, error
 	if v, ok := schema.Extra(); ok {
 		tags = append(tags, fmt.Sprintf("%s:%s", tagExtra, v))
 	}
+	if cs, ok := schema.(dialect.CollationColumnSchema); ok {
+		if v, ok := cs.Charset(); ok {
+			tags = append(tags, fmt.Sprintf("%s:%s", tagCharset, v))
+		}
+		if v, ok := cs.Collation(); ok {
+			tags = append(tags, fmt.Sprintf("%s:%s", tagCollate, v))
+		}
+	}
 	if len(tags) > 0 {
 		field.Tag = &ast.BasicLit{
 			Kind:     token.STRING,
@@ -3,6 +3,7 @@ package migu_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -613,6 +614,27 @@ func TestSpanner(t *testing.T) {
 			}
 		})
 
+		t.Run("charset and collate tags", func(t *testing.T) {
+			for _, v := range []struct {
+				column string
+				expect string
+			}{
+				{"Name string `migu:\"charset:utf8mb4\"`", "`charset` tag is not supported by the dialect"},
+				{"Name string `migu:\"collate:utf8mb4_bin\"`", "`collate` tag is not supported by the dialect"},
+			} {
+				src := "package migu_test\n" +
+					"//+migu\n" +
+					"type User struct {\n" +
+					"ID int64 `migu:\"pk\"`\n" +
+					v.column + "\n" +
+					"}"
+				_, err := migu.Diff(d, "", src)
+				if err == nil || !strings.Contains(err.Error(), v.expect) {
+					t.Errorf("Diff(..., %q) => %v; want error %q", v.column, err, v.expect)
+				}
+			}
+		})
+
 		t.Run("type tag", func(t *testing.T) {
 			t.Run("sequential", func(t *testing.T) {
 				defer cleanup(t)
@@ -1010,6 +1032,49 @@ func TestSpanner(t *testing.T) {
 		})
 	})
 
+	t.Run("Sync", func(t *testing.T) {
+		d := dialect.NewSpanner(dsn)
+		t.Run("partially applied change", func(t *testing.T) {
+			defer cleanup(t)
+			if err := exec([]string{
+				"CREATE TABLE `user` (\n" +
+					"  `id` INT64 NOT NULL,\n" +
+					"  `name` STRING(MAX)\n" +
+					") PRIMARY KEY (`id`)",
+			}); err != nil {
+				t.Fatal(err)
+			}
+			src := strings.Join([]string{
+				"package migu_test",
+				"//+migu",
+				"type User struct {",
+				"	ID   int64  `migu:\"pk\"`",
+				"	Name string `migu:\"extra:allow_commit_timestamp = true\"`",
+				"}",
+			}, "\n")
+			err := migu.Sync(d, "", src)
+			var aerr *migu.ApplyError
+			if !errors.As(err, &aerr) {
+				t.Fatalf("Sync(...) => %#v; want *migu.ApplyError", err)
+			}
+			actual := [][]string{aerr.Applied, aerr.NotApplied, (&migu.Plan{Changes: aerr.Compensations}).SQLs()}
+			expect := [][]string{
+				{
+					"ALTER TABLE `user` ALTER COLUMN `name` STRING(MAX) NOT NULL",
+				},
+				{
+					"ALTER TABLE `user` ALTER COLUMN `name` SET OPTIONS (allow_commit_timestamp = true)",
+				},
+				{
+					"ALTER TABLE `user` ALTER COLUMN `name` STRING(MAX)",
+				},
+			}
+			if diff := cmp.Diff(actual, expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		})
+	})
+
 	t.Run("Fprint", func(t *testing.T) {
 		d := dialect.NewSpanner(dsn)
 		for _, v := range []struct {
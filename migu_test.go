@@ -2,15 +2,20 @@ package migu_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/naoina/migu"
 	"github.com/naoina/migu/dialect"
 )
@@ -1175,6 +1180,7 @@ func TestMySQL(t *testing.T) {
 				{8, `//+migu table:"a" a:`, `migu: invalid annotation: //+migu table:"a" a:`},
 				{9, `//+migu table:"a`, `migu: invalid annotation: string not terminated: //+migu table:"a`},
 				{10, `//+migu table: "a"`, `migu: invalid annotation: value not given: //+migu table: "a"`},
+				{11, `//+migu managed:"no"`, `migu: invalid value of managed annotation: no`},
 			} {
 				v := v
 				t.Run(fmt.Sprintf("invalid annotation/%v", v.i), func(t *testing.T) {
@@ -1223,6 +1229,1255 @@ func TestMySQL(t *testing.T) {
 		})
 	})
 
+	t.Run("ModifyPrimaryKeySQL", func(t *testing.T) {
+		d := dialect.NewMySQL(db).(dialect.PrimaryKeyModifier)
+		for _, v := range []struct {
+			oldPks []dialect.Field
+			newPks []dialect.Field
+			expect []string
+		}{
+			{
+				oldPks: nil,
+				newPks: []dialect.Field{{Table: "user", Name: "id"}},
+				expect: []string{"ALTER TABLE `user` ADD PRIMARY KEY (`id`)"},
+			},
+			{
+				oldPks: []dialect.Field{{Table: "user", Name: "id"}},
+				newPks: []dialect.Field{{Table: "user", Name: "id"}, {Table: "user", Name: "name"}},
+				expect: []string{"ALTER TABLE `user` DROP PRIMARY KEY, ADD PRIMARY KEY (`id`, `name`)"},
+			},
+			{
+				oldPks: []dialect.Field{{Table: "user", Name: "id"}},
+				newPks: nil,
+				expect: []string{"ALTER TABLE `user` DROP PRIMARY KEY"},
+			},
+		} {
+			actual := d.ModifyPrimaryKeySQL(v.oldPks, v.newPks)
+			if diff := cmp.Diff(actual, v.expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		}
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		d := dialect.NewMySQL(db)
+		t.Run("partially applied", func(t *testing.T) {
+			before(t)
+			defer exec([]string{"DROP TABLE IF EXISTS `guest`"})
+			src := strings.Join([]string{
+				"package migu_test",
+				"//+migu",
+				"type Guest struct {",
+				"	Name string",
+				"}",
+				"//+migu",
+				"type User struct {",
+				"	Name string `migu:\"type:unknowntype\"`",
+				"}",
+			}, "\n")
+			err := migu.Sync(d, "", src)
+			var aerr *migu.ApplyError
+			if !errors.As(err, &aerr) {
+				t.Fatalf("Sync(...) => %#v; want *migu.ApplyError", err)
+			}
+			actual := [][]string{aerr.Applied, aerr.NotApplied, (&migu.Plan{Changes: aerr.Compensations}).SQLs()}
+			expect := [][]string{
+				{
+					"CREATE TABLE `guest` (\n" +
+						"  `name` VARCHAR(255) NOT NULL\n" +
+						")",
+				},
+				{
+					"CREATE TABLE `user` (\n" +
+						"  `name` UNKNOWNTYPE NOT NULL\n" +
+						")",
+				},
+				{
+					"DROP TABLE `guest`",
+				},
+			}
+			if diff := cmp.Diff(actual, expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		})
+
+		t.Run("state of partially applied sync", func(t *testing.T) {
+			before(t)
+			defer exec([]string{"DROP TABLE IF EXISTS `guest`"})
+			ctx := context.Background()
+			src := strings.Join([]string{
+				"package migu_test",
+				"//+migu",
+				"type Guest struct {",
+				"	Name string",
+				"}",
+				"//+migu",
+				"type User struct {",
+				"	Name string `migu:\"type:unknowntype\"`",
+				"}",
+			}, "\n")
+			plan, err := migu.MakePlan(ctx, d, "", src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := &migu.State{Database: "migu_test", Plan: plan}
+			err = migu.Apply(ctx, d, state.Remaining(), nil)
+			var aerr *migu.ApplyError
+			if !errors.As(err, &aerr) {
+				t.Fatalf("Apply(...) => %#v; want *migu.ApplyError", err)
+			}
+			state.Applied += len(aerr.Applied)
+			dir, err := ioutil.TempDir("", "migu")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			filename := filepath.Join(dir, "state.json")
+			if err := state.WriteFile(filename); err != nil {
+				t.Fatal(err)
+			}
+			read, err := migu.ReadStateFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(read.Remaining().SQLs(), aerr.NotApplied); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		})
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		d := dialect.NewMySQL(db)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		if err := migu.SyncContext(ctx, d, "", src); !errors.Is(err, context.Canceled) {
+			t.Errorf("SyncContext(...) => %#v; want %#v", err, context.Canceled)
+		}
+		if _, err := migu.DiffContext(ctx, d, "", src); !errors.Is(err, context.Canceled) {
+			t.Errorf("DiffContext(...) => %#v; want %#v", err, context.Canceled)
+		}
+		if _, err := migu.MakePlan(ctx, d, "", src); !errors.Is(err, context.Canceled) {
+			t.Errorf("MakePlan(...) => %#v; want %#v", err, context.Canceled)
+		}
+		if err := migu.Apply(ctx, d, &migu.Plan{}, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("Apply(...) => %#v; want %#v", err, context.Canceled)
+		}
+		t.Run("schema is not changed", func(t *testing.T) {
+			before(t)
+			if err := migu.SyncContext(ctx, d, "", src); !errors.Is(err, context.Canceled) {
+				t.Fatalf("SyncContext(...) => %#v; want %#v", err, context.Canceled)
+			}
+			actual, err := migu.Diff(d, "", src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect := []string{
+				"CREATE TABLE `user` (\n" +
+					"  `name` VARCHAR(255) NOT NULL\n" +
+					")",
+			}
+			if diff := cmp.Diff(actual, expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		})
+	})
+
+	t.Run("ApplyError", func(t *testing.T) {
+		err := &migu.ApplyError{
+			Applied:    []string{"ALTER TABLE `user` DROP `age`", "DROP TABLE `guest`"},
+			NotApplied: []string{"CREATE TABLE `post` (`title` UNKNOWNTYPE NOT NULL)"},
+			Compensations: []*migu.Change{
+				{Kind: migu.AddColumn, Table: "user", SQLs: []string{"ALTER TABLE `user` ADD `age` INT NOT NULL"}, Irreversible: true},
+			},
+			Unreversed: []*migu.Change{
+				{Kind: migu.DropTable, Table: "guest", SQLs: []string{"DROP TABLE `guest`"}},
+			},
+			Err: errors.New("unknown type"),
+		}
+		expect := strings.Join([]string{
+			"migu: schema has been partially migrated: unknown type",
+			"applied statements:",
+			"  ALTER TABLE `user` DROP `age`;",
+			"  DROP TABLE `guest`;",
+			"not applied statements:",
+			"  CREATE TABLE `post` (`title` UNKNOWNTYPE NOT NULL);",
+			"compensating statements (incomplete):",
+			"  -- WARNING: add_column of user cannot restore the data.",
+			"  ALTER TABLE `user` ADD `age` INT NOT NULL;",
+			"changes that cannot be reverted:",
+			"  -- drop_table of guest",
+			"  DROP TABLE `guest`;",
+		}, "\n")
+		if diff := cmp.Diff(err.Error(), expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("State", func(t *testing.T) {
+		d := dialect.NewMySQL(nil)
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type Guest struct {",
+			"	Name string",
+			"}",
+			"//+migu",
+			"type User struct {",
+			"	Name string `migu:\"index\"`",
+			"}",
+		}, "\n")
+		plan, err := migu.MakePlan(context.Background(), d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqls := plan.SQLs()
+		if len(sqls) != 3 {
+			t.Fatalf("MakePlan(...).SQLs() => %q; want 3 statements", sqls)
+		}
+		t.Run("Skip", func(t *testing.T) {
+			for i := 0; i <= len(sqls); i++ {
+				if diff := cmp.Diff(plan.Skip(i).SQLs(), sqls[i:], cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("Skip(%d): (-got +want)\n%v", i, diff)
+				}
+			}
+			if _, err := migu.Reverse(plan.Skip(1)); err != nil {
+				t.Errorf("Reverse(Skip(1)) => %v; want nil because the dialect is kept", err)
+			}
+		})
+		t.Run("file", func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "migu")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			filename := filepath.Join(dir, "state.json")
+			state := &migu.State{Database: "migu_test", SourceHash: "hash", Applied: 1, Plan: plan}
+			if err := state.WriteFile(filename); err != nil {
+				t.Fatal(err)
+			}
+			read, err := migu.ReadStateFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := []interface{}{read.Database, read.SourceHash, read.Applied, read.Remaining().SQLs()}
+			expect := []interface{}{"migu_test", "hash", 1, sqls[1:]}
+			if diff := cmp.Diff(actual, expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+			// The plan read from the file has neither the dialect nor the
+			// definitions, so it cannot be reversed, but it must not panic.
+			if _, err := migu.Reverse(read.Remaining()); err == nil {
+				t.Errorf("Reverse(ReadStateFile(...).Remaining()) => nil; want error")
+			}
+			if err := migu.Apply(context.Background(), d, read.Remaining(), nil); !errors.Is(err, dialect.ErrOffline) {
+				t.Errorf("Apply(...) => %#v; want %#v", err, dialect.ErrOffline)
+			}
+		})
+		t.Run("SourceHash", func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "migu")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			filename := filepath.Join(dir, "schema.go")
+			hash := func() string {
+				t.Helper()
+				h, err := migu.SourceHash(filename, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return h
+			}
+			if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+				t.Fatal(err)
+			}
+			h1 := hash()
+			if h2 := hash(); h1 != h2 {
+				t.Errorf("SourceHash(...) => %q; want %q for the same source", h2, h1)
+			}
+			if h, err := migu.SourceHash("", src); err != nil || h == h1 {
+				t.Errorf("SourceHash(\"\", src) => %q, %v; want the hash of src that differs from the one of the file", h, err)
+			}
+			if err := ioutil.WriteFile(filename, []byte(src+"\n// changed"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if h3 := hash(); h3 == h1 {
+				t.Errorf("SourceHash(...) => %q; want the different hash after the source has been changed", h3)
+			}
+		})
+	})
+
+	t.Run("plan file", func(t *testing.T) {
+		before(t)
+		defer exec([]string{"DROP TABLE IF EXISTS `guest`"})
+		ctx := context.Background()
+		d := dialect.NewMySQL(db)
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		plan, err := migu.MakePlan(ctx, d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fingerprint, err := migu.Fingerprint(ctx, d, plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir, err := ioutil.TempDir("", "migu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		filename := filepath.Join(dir, "plan.json")
+		if err := (&migu.PlanFile{Fingerprint: fingerprint, Plan: plan}).WriteFile(filename); err != nil {
+			t.Fatal(err)
+		}
+		f, err := migu.ReadPlanFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(f.Plan.SQLs(), plan.SQLs()); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+		if err := exec([]string{"CREATE TABLE `guest` (`name` VARCHAR(255) NOT NULL)"}); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := migu.Fingerprint(ctx, d, f.Plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != f.Fingerprint {
+			t.Errorf("Fingerprint(...) => %q; want %q because the table not in the plan has been changed", actual, f.Fingerprint)
+		}
+		if err := migu.Apply(ctx, d, f.Plan, nil); err != nil {
+			t.Fatal(err)
+		}
+		actualSQLs, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actualSQLs) != 0 {
+			t.Errorf("Diff(...) after Apply => %q; want empty", actualSQLs)
+		}
+		actual, err = migu.Fingerprint(ctx, d, f.Plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual == f.Fingerprint {
+			t.Errorf("Fingerprint(...) => %q; want the different fingerprint after the plan has been applied", actual)
+		}
+	})
+
+	t.Run("Reverse", func(t *testing.T) {
+		d := dialect.NewMySQL(db)
+		before(t)
+		if err := exec([]string{
+			"CREATE TABLE `user` (\n" +
+				"  `name` VARCHAR(255) NOT NULL,\n" +
+				"  `age` INT NOT NULL\n" +
+				")",
+		}); err != nil {
+			t.Fatal(err)
+		}
+		defer exec([]string{"DROP TABLE `user`"})
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name  string `migu:\"type:varchar(100)\"`",
+			"	Email string `migu:\"index\"`",
+			"}",
+		}, "\n")
+		plan, err := migu.MakePlan(context.Background(), d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reversed, err := migu.Reverse(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		type change struct {
+			Kind         migu.ChangeKind
+			SQLs         []string
+			Irreversible bool
+		}
+		var actual []change
+		for _, c := range reversed.Changes {
+			actual = append(actual, change{c.Kind, c.SQLs, c.Irreversible})
+		}
+		expect := []change{
+			{migu.DropIndex, []string{"DROP INDEX `user_email` ON `user`"}, false},
+			{migu.AddColumn, []string{"ALTER TABLE `user` ADD `age` INT NOT NULL"}, true},
+			{migu.DropColumn, []string{"ALTER TABLE `user` DROP `email`"}, false},
+			{migu.ModifyColumn, []string{"ALTER TABLE `user` CHANGE `name` `name` VARCHAR(255) NOT NULL"}, true},
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("Reverse with MakeSourcePlan", func(t *testing.T) {
+		d := dialect.NewMySQL(nil)
+		oldSrc := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name  *string",
+			"	Token string",
+			"	Age   int `migu:\"index\"`",
+			"	Group int `migu:\"index:user_group_level\"`",
+			"	Level int `migu:\"index:user_group_level\"`",
+			"}",
+		}, "\n")
+		newSrc := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name  string",
+			"	Token string `migu:\"collate:utf8mb4_bin\"`",
+			"}",
+		}, "\n")
+		plan, err := migu.MakeSourcePlan(d, "", oldSrc, "", newSrc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reversed, err := migu.Reverse(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		type change struct {
+			Kind         migu.ChangeKind
+			SQLs         []string
+			Irreversible bool
+		}
+		var actual []change
+		for _, c := range reversed.Changes {
+			actual = append(actual, change{c.Kind, c.SQLs, c.Irreversible})
+		}
+		expect := []change{
+			{migu.AddColumn, []string{"ALTER TABLE `user` ADD `level` INT NOT NULL"}, true},
+			{migu.AddColumn, []string{"ALTER TABLE `user` ADD `group` INT NOT NULL"}, true},
+			{migu.CreateIndex, []string{"CREATE INDEX `user_group_level` ON `user` (`group`,`level`)"}, false},
+			{migu.AddColumn, []string{"ALTER TABLE `user` ADD `age` INT NOT NULL"}, true},
+			{migu.CreateIndex, []string{"CREATE INDEX `user_age` ON `user` (`age`)"}, false},
+			{migu.ModifyColumn, []string{"ALTER TABLE `user` CHANGE `token` `token` VARCHAR(255) NOT NULL"}, true},
+			{migu.ModifyColumn, []string{"ALTER TABLE `user` CHANGE `name` `name` VARCHAR(255)"}, true},
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("MakeSourcePlan", func(t *testing.T) {
+		d := dialect.NewMySQL(nil)
+		oldSrc := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"}",
+			"//+migu",
+			"type Guest struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		newSrc := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"	Age  int `migu:\"index\"`",
+			"}",
+			"//+migu",
+			"type Post struct {",
+			"	ID int64 `migu:\"pk\"`",
+			"}",
+		}, "\n")
+		plan, err := migu.MakeSourcePlan(d, "", oldSrc, "", newSrc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := plan.SQLs()
+		expect := []string{
+			"CREATE TABLE `post` (\n" +
+				"  `id` BIGINT NOT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				")",
+			"ALTER TABLE `user` ADD `age` INT NOT NULL",
+			"CREATE INDEX `user_age` ON `user` (`age`)",
+			"DROP TABLE `guest`",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("table filter", func(t *testing.T) {
+		snapshot := dialect.NewSnapshot(nil)
+		for _, name := range []string{"guest", "schema_migrations", "tmp_user"} {
+			snapshot.Tables = append(snapshot.Tables, &dialect.SnapshotTable{
+				Name: name,
+				Columns: []*dialect.SnapshotColumn{
+					{Name: "id", Type: "bigint", DataType: "bigint"},
+				},
+			})
+		}
+		d := dialect.NewMySQL(nil,
+			dialect.WithSnapshot(snapshot),
+			dialect.WithTableFilter(nil, []string{"schema_migrations", "tmp_*"}))
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"}",
+			"//+migu",
+			"type TmpPost struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		plan, err := migu.MakeSourcePlan(d, "", strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type Guest struct {",
+			"	Name string",
+			"}",
+			"//+migu",
+			"type TmpGuest struct {",
+			"	Name string",
+			"}",
+		}, "\n"), "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := plan.SQLs()
+		expect := []string{
+			"CREATE TABLE `user` (\n" +
+				"  `name` VARCHAR(255) NOT NULL\n" +
+				")",
+			"DROP TABLE `guest`",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+		var buf bytes.Buffer
+		if err := migu.FprintSQL(&buf, d); err != nil {
+			t.Fatal(err)
+		}
+		actualSQL := buf.String()
+		expectSQL := "CREATE TABLE `guest` (\n" +
+			"  `id` BIGINT NOT NULL\n" +
+			");\n"
+		if diff := cmp.Diff(actualSQL, expectSQL); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("Fingerprint", func(t *testing.T) {
+		ctx := context.Background()
+		comment := "the users"
+		newSnapshot := func(guestType string, userComment *string) *dialect.Snapshot {
+			return &dialect.Snapshot{
+				Tables: []*dialect.SnapshotTable{
+					{
+						Name: "guest",
+						Columns: []*dialect.SnapshotColumn{
+							{Name: "name", Type: guestType, DataType: "varchar"},
+						},
+					},
+					{
+						Name:    "user",
+						Comment: userComment,
+						Columns: []*dialect.SnapshotColumn{
+							{Name: "name", Type: "varchar(255)", DataType: "varchar"},
+						},
+					},
+				},
+			}
+		}
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"	Age  int",
+			"}",
+			"//+migu",
+			"type Guest struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		d := dialect.NewMySQL(nil, dialect.WithSnapshot(newSnapshot("varchar(255)", nil)))
+		plan, err := migu.MakePlan(ctx, d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fingerprint, err := migu.Fingerprint(ctx, d, plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range []struct {
+			name  string
+			d     dialect.Dialect
+			match bool
+		}{
+			{"same schema", dialect.NewMySQL(nil, dialect.WithSnapshot(newSnapshot("varchar(255)", nil))), true},
+			{"table not in plan changed", dialect.NewMySQL(nil, dialect.WithSnapshot(newSnapshot("varchar(100)", nil))), true},
+			{"table in plan changed", dialect.NewMySQL(nil), false},
+			{"table comment in plan changed", dialect.NewMySQL(nil, dialect.WithSnapshot(newSnapshot("varchar(255)", &comment))), false},
+		} {
+			v := v
+			t.Run(v.name, func(t *testing.T) {
+				actual, err := migu.Fingerprint(ctx, v.d, plan)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if match := actual == fingerprint; match != v.match {
+					t.Errorf("Fingerprint(...) == the original => %v; want %v", match, v.match)
+				}
+			})
+		}
+	})
+
+	t.Run("ignore columns", func(t *testing.T) {
+		snapshot := &dialect.Snapshot{
+			Tables: []*dialect.SnapshotTable{
+				{
+					Name: "post",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "title", Type: "varchar(255)", DataType: "varchar"},
+						{Name: "_synced_at", Type: "datetime", DataType: "datetime"},
+					},
+				},
+				{
+					Name: "user",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "name", Type: "varchar(255)", DataType: "varchar"},
+						{Name: "_version", Type: "bigint", DataType: "bigint", Index: &dialect.SnapshotIndex{Name: "user__version"}},
+					},
+				},
+			},
+		}
+		d := dialect.NewMySQL(nil,
+			dialect.WithSnapshot(snapshot),
+			dialect.WithIgnoreColumns([]string{"*._synced_at"}))
+		src := strings.Join([]string{
+			"package migu_test",
+			`//+migu ignore_columns:"_version"`,
+			"type User struct {",
+			"	Name string",
+			"	Age  int",
+			"}",
+			"//+migu",
+			"type Post struct {",
+			"	Title string",
+			"}",
+			`//+migu managed:"false"`,
+			"type Guest struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		actual, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"ALTER TABLE `user` ADD `age` INT NOT NULL",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("ignore columns of schema-qualified table", func(t *testing.T) {
+		snapshot := &dialect.Snapshot{
+			Tables: []*dialect.SnapshotTable{
+				{
+					Name: "analytics.events",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "name", Type: "varchar(255)", DataType: "varchar"},
+						{Name: "_version", Type: "bigint", DataType: "bigint"},
+					},
+				},
+			},
+		}
+		d := dialect.NewMySQL(nil,
+			dialect.WithSnapshot(snapshot),
+			dialect.WithIgnoreColumns([]string{"analytics.events._version"}))
+		src := strings.Join([]string{
+			"package migu_test",
+			`//+migu schema:"analytics"`,
+			"type Events struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		actual, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actual) != 0 {
+			t.Errorf("Diff(...) => %q; want empty because `_version' is ignored", actual)
+		}
+	})
+
+	t.Run("multiple schemas", func(t *testing.T) {
+		d := dialect.NewMySQL(nil)
+		oldSrc := strings.Join([]string{
+			"CREATE TABLE `analytics`.`event` (",
+			"  `name` VARCHAR(255) NOT NULL",
+			");",
+			"CREATE TABLE archive.user (",
+			"  `name` VARCHAR(255) NOT NULL",
+			");",
+		}, "\n")
+		newSrc := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"}",
+			`//+migu schema:"analytics"`,
+			"type Event struct {",
+			"	Name string",
+			"	At   int `migu:\"index\"`",
+			"}",
+		}, "\n")
+		plan, err := migu.MakeSourcePlan(d, "schema.sql", oldSrc, "", newSrc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := plan.SQLs()
+		expect := []string{
+			"ALTER TABLE `analytics`.`event` ADD `at` INT NOT NULL",
+			"CREATE INDEX `event_at` ON `analytics`.`event` (`at`)",
+			"CREATE TABLE `user` (\n" +
+				"  `name` VARCHAR(255) NOT NULL\n" +
+				")",
+			"DROP TABLE `archive`.`user`",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("table comment", func(t *testing.T) {
+		comment := "the users"
+		snapshot := &dialect.Snapshot{
+			Tables: []*dialect.SnapshotTable{
+				{
+					Name:    "post",
+					Comment: &comment,
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "title", Type: "varchar(255)", DataType: "varchar"},
+					},
+				},
+				{
+					Name:    "user",
+					Comment: &comment,
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "name", Type: "varchar(255)", DataType: "varchar"},
+					},
+				},
+			},
+		}
+		d := dialect.NewMySQL(nil, dialect.WithSnapshot(snapshot))
+		src := strings.Join([]string{
+			"package migu_test",
+			"// User is the user's account.",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"}",
+			"// Post is a post.",
+			`//+migu comment:""`,
+			"type Post struct {",
+			"	Title string",
+			"}",
+			`//+migu comment:"the tags"`,
+			"type Tag struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		actual, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"ALTER TABLE `post` COMMENT ''",
+			"CREATE TABLE `tag` (\n" +
+				"  `name` VARCHAR(255) NOT NULL\n" +
+				") COMMENT 'the tags'",
+			"ALTER TABLE `user` COMMENT 'User is the user''s account.'",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+		var buf bytes.Buffer
+		if err := migu.Fprint(&buf, d); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{
+			"// the users\n//+migu\ntype Post struct {",
+			"// the users\n//+migu\ntype User struct {",
+		} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("Fprint(...) => %q; want to contain %q", buf.String(), want)
+			}
+		}
+	})
+
+	t.Run("table option", func(t *testing.T) {
+		snapshot := &dialect.Snapshot{
+			Tables: []*dialect.SnapshotTable{
+				{
+					Name:   "post",
+					Option: "ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_general_ci ROW_FORMAT=DYNAMIC",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "title", Type: "varchar(255)", DataType: "varchar"},
+					},
+				},
+				{
+					Name:   "user",
+					Option: "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci ROW_FORMAT=DYNAMIC",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "name", Type: "varchar(255)", DataType: "varchar"},
+					},
+				},
+			},
+		}
+		d := dialect.NewMySQL(nil, dialect.WithSnapshot(snapshot))
+		src := strings.Join([]string{
+			"package migu_test",
+			`//+migu option:"ENGINE=MyISAM DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_bin"`,
+			"type User struct {",
+			"	Name string",
+			"}",
+			`//+migu option:"engine=innodb, DEFAULT CHARSET=utf8mb3 ROW_FORMAT=dynamic"`,
+			"type Post struct {",
+			"	Title string",
+			"}",
+		}, "\n")
+		plan, err := migu.MakePlan(context.Background(), d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := plan.SQLs()
+		expect := []string{
+			"ALTER TABLE `user` ENGINE=MyISAM, CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_bin",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+		reversed, err := migu.Reverse(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual = reversed.SQLs()
+		expect = []string{
+			"ALTER TABLE `user` ENGINE=InnoDB, CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+		if !reversed.Changes[0].Irreversible {
+			t.Errorf("Reverse(...).Changes[0].Irreversible => false; want true because the table has been converted")
+		}
+	})
+
+	t.Run("table conversion with column charset", func(t *testing.T) {
+		charset, collation := "ascii", "ascii_bin"
+		snapshot := &dialect.Snapshot{
+			Tables: []*dialect.SnapshotTable{
+				{
+					Name:   "user",
+					Option: "ENGINE=InnoDB DEFAULT CHARSET=latin1",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "name", Type: "varchar(255)", DataType: "varchar"},
+						{Name: "code", Type: "varchar(255)", DataType: "varchar", Charset: &charset, Collation: &collation},
+					},
+				},
+			},
+		}
+		d := dialect.NewMySQL(nil, dialect.WithSnapshot(snapshot))
+		src := strings.Join([]string{
+			"package migu_test",
+			`//+migu option:"ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"`,
+			"type User struct {",
+			"	Name string",
+			"	Code string `migu:\"charset:ascii,collate:ascii_bin\"`",
+			"}",
+		}, "\n")
+		actual, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"ALTER TABLE `user` CONVERT TO CHARACTER SET utf8mb4",
+			"ALTER TABLE `user` CHANGE `code` `code` VARCHAR(255) CHARACTER SET ascii COLLATE ascii_bin NOT NULL",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("column charset", func(t *testing.T) {
+		charset, collation := "utf8mb4", "utf8mb4_bin"
+		snapshot := &dialect.Snapshot{
+			Tables: []*dialect.SnapshotTable{
+				{
+					Name:   "user",
+					Option: "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "name", Type: "varchar(255)", DataType: "varchar"},
+						{Name: "token", Type: "varchar(255)", DataType: "varchar", Charset: &charset, Collation: &collation},
+						{Name: "note", Type: "varchar(255)", DataType: "varchar", Charset: &charset, Collation: &collation},
+					},
+				},
+			},
+		}
+		d := dialect.NewMySQL(nil, dialect.WithSnapshot(snapshot))
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name  string `migu:\"charset:utf8mb4\"`",
+			"	Token string `migu:\"collate:utf8mb4_bin\"`",
+			"	Note  string",
+			"	Code  string `migu:\"charset:ascii,collate:ascii_bin\"`",
+			"}",
+		}, "\n")
+		actual, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"ALTER TABLE `user` CHANGE `note` `note` VARCHAR(255) NOT NULL",
+			"ALTER TABLE `user` ADD `code` VARCHAR(255) CHARACTER SET ascii COLLATE ascii_bin NOT NULL",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+		var buf bytes.Buffer
+		if err := migu.Fprint(&buf, d); err != nil {
+			t.Fatal(err)
+		}
+		if want := "`migu:\"type:varchar(255),charset:utf8mb4,collate:utf8mb4_bin\"`"; !strings.Contains(buf.String(), want) {
+			t.Errorf("Fprint(...) => %q; want to contain %q", buf.String(), want)
+		}
+	})
+
+	t.Run("default collation of charset", func(t *testing.T) {
+		charset, collation := "utf8mb4", "utf8mb4_0900_ai_ci"
+		snapshot := &dialect.Snapshot{
+			Tables: []*dialect.SnapshotTable{
+				{
+					Name:   "user",
+					Option: "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin",
+					Columns: []*dialect.SnapshotColumn{
+						{Name: "name", Type: "varchar(255)", DataType: "varchar", Charset: &charset, Collation: &collation},
+						{Name: "token", Type: "varchar(255)", DataType: "varchar", Charset: &charset, Collation: &collation},
+						{Name: "note", Type: "varchar(255)", DataType: "varchar", Charset: &charset, Collation: &collation},
+					},
+				},
+			},
+			DefaultCollations: map[string]string{
+				"utf8mb4": "utf8mb4_0900_ai_ci",
+			},
+		}
+		d := dialect.NewMySQL(nil, dialect.WithSnapshot(snapshot))
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name  string `migu:\"collate:utf8mb4_0900_ai_ci\"`",
+			"	Token string `migu:\"charset:utf8mb4\"`",
+			"	Note  string",
+			"}",
+		}, "\n")
+		actual, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"ALTER TABLE `user` CHANGE `note` `note` VARCHAR(255) NOT NULL",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		d := dialect.NewMySQL(nil, dialect.WithServerVersion("8.0.34"))
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	Name string",
+			"}",
+		}, "\n")
+		actual, err := migu.Diff(d, "", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"CREATE TABLE `user` (\n" +
+				"  `name` VARCHAR(255) NOT NULL\n" +
+				")",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+		if err := migu.Sync(d, "", src); !errors.Is(err, dialect.ErrOffline) {
+			t.Errorf("Sync(...) => %#v; want %#v", err, dialect.ErrOffline)
+		}
+	})
+
+	t.Run("DDL", func(t *testing.T) {
+		d := dialect.NewMySQL(nil)
+		src := strings.Join([]string{
+			"-- comment",
+			"CREATE TABLE IF NOT EXISTS `user` (",
+			"  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,",
+			"  name VARCHAR(255) NOT NULL DEFAULT 'it''s' COMMENT 'the name',",
+			"  `updated_at` DATETIME ON UPDATE CURRENT_TIMESTAMP(),",
+			"  `token` VARCHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,",
+			"  PRIMARY KEY (`id`),",
+			"  KEY `name_index` (`name`)",
+			") ENGINE=InnoDB COMMENT='the users';",
+			"CREATE UNIQUE INDEX `user_updated_at` ON `user` (`updated_at`);",
+		}, "\n")
+		actual, err := migu.Diff(d, "schema.sql", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string{
+			"CREATE TABLE `user` (\n" +
+				"  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n" +
+				"  `name` VARCHAR(255) NOT NULL DEFAULT 'it''s' COMMENT 'the name',\n" +
+				"  `updated_at` DATETIME ON UPDATE CURRENT_TIMESTAMP,\n" +
+				"  `token` VARCHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB COMMENT 'the users'",
+			"CREATE INDEX `name_index` ON `user` (`name`)",
+			"CREATE UNIQUE INDEX `user_updated_at` ON `user` (`updated_at`)",
+		}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("DDL with unsupported index", func(t *testing.T) {
+		d := dialect.NewMySQL(nil)
+		for _, v := range []struct {
+			name   string
+			src    string
+			expect string
+		}{
+			{"column order", strings.Join([]string{
+				"CREATE TABLE `user` (",
+				"  `a` INT NOT NULL,",
+				"  `b` INT NOT NULL,",
+				"  KEY `idx` (`b`, `a`)",
+				");",
+			}, "\n"), "migu: schema.sql: line 4: the columns of index `idx' must be in the same order as the columns of table `user'"},
+			{"multiple indexes", strings.Join([]string{
+				"CREATE TABLE `user` (",
+				"  `a` INT NOT NULL,",
+				"  KEY `idx1` (`a`)",
+				");",
+				"CREATE INDEX `idx2` ON `user` (`a`);",
+			}, "\n"), "migu: schema.sql: line 5: column `a' cannot have multiple indexes: `idx1' and `idx2'"},
+		} {
+			v := v
+			t.Run(v.name, func(t *testing.T) {
+				_, err := migu.Diff(d, "schema.sql", v.src)
+				if err == nil {
+					t.Fatalf("Diff(...) => nil; want error")
+				}
+				if diff := cmp.Diff(err.Error(), v.expect); diff != "" {
+					t.Errorf("(-got +want)\n%v", diff)
+				}
+			})
+		}
+	})
+
+	t.Run("schema file", func(t *testing.T) {
+		d := dialect.NewMySQL(nil)
+		expect := []string{
+			"CREATE TABLE `user` (\n" +
+				"  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n" +
+				"  `name` VARCHAR(255) NOT NULL DEFAULT 'alice' COMMENT 'the name',\n" +
+				"  `email` VARCHAR(255),\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB",
+			"CREATE INDEX `name_email_index` ON `user` (`name`,`email`)",
+			"CREATE UNIQUE INDEX `user_email` ON `user` (`email`)",
+		}
+		for _, v := range []struct {
+			filename string
+			src      string
+		}{
+			{"schema.yaml", strings.Join([]string{
+				"tables:",
+				"- name: user",
+				"  columns:",
+				"  - name: id",
+				"    type: bigint unsigned",
+				"    auto_increment: true",
+				"  - name: name",
+				"    type: varchar",
+				"    default: alice",
+				"    comment: the name",
+				"  - name: email",
+				"    type: VARCHAR(255)",
+				"    nullable: true",
+				"  primary_key: [id]",
+				"  indexes:",
+				"  - columns: [email]",
+				"    unique: true",
+				"  - name: name_email_index",
+				"    columns: [name, email]",
+				"  option: ENGINE=InnoDB",
+			}, "\n")},
+			{"schema.json", `{"tables": [{` +
+				`"name": "user",` +
+				`"columns": [` +
+				`{"name": "id", "type": "bigint unsigned", "auto_increment": true},` +
+				`{"name": "name", "type": "varchar", "default": "alice", "comment": "the name"},` +
+				`{"name": "email", "type": "VARCHAR(255)", "nullable": true}` +
+				`],` +
+				`"primary_key": ["id"],` +
+				`"indexes": [` +
+				`{"columns": ["email"], "unique": true},` +
+				`{"name": "name_email_index", "columns": ["name", "email"]}` +
+				`],` +
+				`"option": "ENGINE=InnoDB"` +
+				`}]}`},
+		} {
+			v := v
+			t.Run(v.filename, func(t *testing.T) {
+				actual, err := migu.Diff(d, v.filename, v.src)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(actual, expect); diff != "" {
+					t.Errorf("(-got +want)\n%v", diff)
+				}
+			})
+		}
+		t.Run("index column order", func(t *testing.T) {
+			src := strings.Join([]string{
+				"tables:",
+				"- name: user",
+				"  columns:",
+				"  - name: name",
+				"    type: varchar",
+				"  - name: email",
+				"    type: varchar",
+				"  indexes:",
+				"  - name: email_name_index",
+				"    columns: [email, name]",
+			}, "\n")
+			_, err := migu.Diff(d, "schema.yaml", src)
+			if err == nil {
+				t.Fatalf("Diff(...) => nil; want error")
+			}
+			expect := "migu: schema.yaml: the columns of index `email_name_index' must be in the same order as the columns of table `user'"
+			if diff := cmp.Diff(err.Error(), expect); diff != "" {
+				t.Errorf("(-got +want)\n%v", diff)
+			}
+		})
+	})
+
+	t.Run("FprintSQL", func(t *testing.T) {
+		d := dialect.NewMySQL(db)
+		before(t)
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	ID    int64   `migu:\"pk,autoincrement\"`",
+			"	Name  string  `migu:\"default:alice\"` // the name",
+			"	Email *string `migu:\"unique\"`",
+			"}",
+		}, "\n")
+		if err := migu.Sync(d, "", src); err != nil {
+			t.Fatal(err)
+		}
+		defer exec([]string{"DROP TABLE `user`"})
+		var buf bytes.Buffer
+		if err := migu.FprintSQL(&buf, d); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := migu.Diff(d, "schema.sql", buf.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := []string(nil)
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		d := dialect.NewMySQL(db)
+		before(t)
+		src := strings.Join([]string{
+			"package migu_test",
+			"//+migu",
+			"type User struct {",
+			"	ID    int64  `migu:\"pk,autoincrement\"`",
+			"	Name  string `migu:\"default:alice\"`",
+			"	Email string `migu:\"unique\"`",
+			"}",
+		}, "\n")
+		if err := migu.Sync(d, "", src); err != nil {
+			t.Fatal(err)
+		}
+		defer exec([]string{"DROP TABLE `user`"})
+		schemas, err := d.ColumnSchema()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir, err := ioutil.TempDir("", "migu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		for _, name := range []string{"schema.json", "schema.yaml"} {
+			name := name
+			t.Run(name, func(t *testing.T) {
+				filename := filepath.Join(dir, name)
+				if err := dialect.NewSnapshot(schemas).WriteFile(filename); err != nil {
+					t.Fatal(err)
+				}
+				snapshot, err := dialect.ReadSnapshotFile(filename)
+				if err != nil {
+					t.Fatal(err)
+				}
+				actual, err := migu.Diff(dialect.NewMySQL(nil, dialect.WithSnapshot(snapshot)), "", src)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expect := []string(nil)
+				if diff := cmp.Diff(actual, expect); diff != "" {
+					t.Errorf("(-got +want)\n%v", diff)
+				}
+			})
+		}
+	})
+
 	t.Run("Fprint", func(t *testing.T) {
 		d := dialect.NewMySQL(db)
 		before(t)
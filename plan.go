@@ -0,0 +1,588 @@
+package migu
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// ChangeKind represents the kind of a schema change.
+type ChangeKind string
+
+const (
+	CreateTable      ChangeKind = "create_table"
+	DropTable        ChangeKind = "drop_table"
+	ModifyTable      ChangeKind = "modify_table"
+	AddColumn        ChangeKind = "add_column"
+	DropColumn       ChangeKind = "drop_column"
+	ModifyColumn     ChangeKind = "modify_column"
+	ModifyPrimaryKey ChangeKind = "modify_primary_key"
+	CreateIndex      ChangeKind = "create_index"
+	DropIndex        ChangeKind = "drop_index"
+)
+
+// Change represents a schema change and SQLs to apply it.
+type Change struct {
+	Kind   ChangeKind `json:"kind"`
+	Table  string     `json:"table"`
+	Column string     `json:"column,omitempty"`
+	Index  string     `json:"index,omitempty"`
+	SQLs   []string   `json:"sqls"`
+
+	// Irreversible reports whether the data cannot be restored by the change.
+	// It's set to the changes returned from Reverse.
+	Irreversible bool `json:"irreversible,omitempty"`
+
+	// The definitions before and after the change.
+	// They are used to derive the compensating statements.
+	oldTable *table
+	newTable *table
+	oldField *field
+	newField *field
+	oldPks   []*field
+	newPks   []*field
+	index    *index
+
+	// The indexes that are dropped together with the column by DropColumn.
+	indexes []*index
+}
+
+// Plan is the list of changes for schema synchronization.
+type Plan struct {
+	Changes []*Change `json:"changes"`
+
+	d dialect.Dialect
+}
+
+// PlanFile is the saved plan to apply it later.
+type PlanFile struct {
+	// Fingerprint is the fingerprint of the database schema when the plan was made.
+	Fingerprint string `json:"fingerprint"`
+
+	Plan *Plan `json:"plan"`
+}
+
+// ReadPlanFile reads the saved plan from the file.
+func ReadPlanFile(filename string) (*PlanFile, error) {
+	var f PlanFile
+	if err := readJSONFile(filename, &f); err != nil {
+		return nil, fmt.Errorf("migu: failed to read plan file: %w", err)
+	}
+	if f.Plan == nil {
+		f.Plan = &Plan{}
+	}
+	return &f, nil
+}
+
+// WriteFile writes the saved plan to the file.
+func (f *PlanFile) WriteFile(filename string) error {
+	return writeJSONFile(filename, f)
+}
+
+// Fingerprint returns the fingerprint of the current database schema of the tables changed by the plan.
+// The fingerprint will be changed if any column or index of those tables is changed,
+// or if any of those tables is created or dropped. If d is dialect.TableSchemaReader,
+// it will also be changed if the comment or the option of those tables is changed.
+func Fingerprint(ctx context.Context, d dialect.Dialect, plan *Plan) (string, error) {
+	var schemas []dialect.ColumnSchema
+	var tableSchemas []dialect.TableSchema
+	if tables := plan.tables(); len(tables) > 0 {
+		var err error
+		if schemas, err = d.ColumnSchemaContext(ctx, tables...); err != nil {
+			return "", err
+		}
+		if r, ok := d.(dialect.TableSchemaReader); ok {
+			if tableSchemas, err = r.TableSchemaContext(ctx, tables...); err != nil {
+				return "", err
+			}
+		}
+	}
+	lines := make([]string, 0, len(schemas)+len(tableSchemas))
+	for _, s := range schemas {
+		lines = append(lines, schemaString(s))
+	}
+	for _, s := range tableSchemas {
+		lines = append(lines, tableSchemaString(s))
+	}
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, line := range lines {
+		io.WriteString(h, line)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func schemaString(s dialect.ColumnSchema) string {
+	indexName, unique, hasIndex := s.Index()
+	def, hasDefault := s.Default()
+	extra, hasExtra := s.Extra()
+	comment, hasComment := s.Comment()
+	line := fmt.Sprintf("%q %q %q %q %v %v %q %v %v %q %v %v %q %v %q %v",
+		s.TableName(), s.ColumnName(), s.ColumnType(), s.DataType(),
+		s.IsPrimaryKey(), s.IsAutoIncrement(),
+		indexName, unique, hasIndex,
+		def, hasDefault,
+		s.IsNullable(),
+		extra, hasExtra,
+		comment, hasComment)
+	// The character set and collation are appended only if they are given
+	// to keep the fingerprint of the schema without them.
+	if cs, ok := s.(dialect.CollationColumnSchema); ok {
+		charset, hasCharset := cs.Charset()
+		collation, hasCollation := cs.Collation()
+		if hasCharset || hasCollation {
+			line += fmt.Sprintf(" %q %q", charset, collation)
+		}
+	}
+	return line + "\n"
+}
+
+func tableSchemaString(s dialect.TableSchema) string {
+	comment, hasComment := s.Comment()
+	option, hasOption := s.Option()
+	return fmt.Sprintf("table %q %q %v %q %v\n", s.TableName(), comment, hasComment, option, hasOption)
+}
+
+// tables returns the names of the tables changed by the plan.
+func (p *Plan) tables() []string {
+	var tables []string
+	seen := make(map[string]struct{})
+	for _, c := range p.Changes {
+		if _, exists := seen[c.Table]; exists {
+			continue
+		}
+		seen[c.Table] = struct{}{}
+		tables = append(tables, c.Table)
+	}
+	return tables
+}
+
+func (p *Plan) add(c *Change) {
+	p.Changes = append(p.Changes, c)
+}
+
+// Filter returns a new plan that consists of the changes for which fn returns true.
+func (p *Plan) Filter(fn func(c *Change) bool) *Plan {
+	plan := &Plan{d: p.d}
+	for _, c := range p.Changes {
+		if fn(c) {
+			plan.add(c)
+		}
+	}
+	return plan
+}
+
+// ExcludeTables returns a new plan without the changes of the given tables.
+func (p *Plan) ExcludeTables(tables ...string) *Plan {
+	return p.Filter(func(c *Change) bool {
+		return !inStrings(tables, c.Table)
+	})
+}
+
+// SQLs returns all SQLs of the changes in order.
+func (p *Plan) SQLs() []string {
+	var sqls []string
+	for _, c := range p.Changes {
+		sqls = append(sqls, c.SQLs...)
+	}
+	return sqls
+}
+
+// Reverse returns the plan that reverts the changes of the given plan.
+//
+// The changes of the returned plan are in the reverse order of the given plan.
+// The change that cannot restore the data, e.g. re-creating the dropped table
+// or re-adding the dropped column, is marked as irreversible.
+// Reverse returns an error if any change cannot be reverted because the
+// definitions before and after the change are unknown, e.g. the plan has been
+// read from the file.
+func Reverse(plan *Plan) (*Plan, error) {
+	reversed := &Plan{d: plan.d}
+	for i := len(plan.Changes) - 1; i >= 0; i-- {
+		changes, err := plan.Changes[i].reverse(plan.d)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range changes {
+			reversed.add(c)
+		}
+	}
+	return reversed, nil
+}
+
+// compensations returns the changes to revert the first applied statements of the plan by d.
+// If the last change has been applied partially, only its applied statements are reverted.
+// The applied changes that cannot be reverted are returned as unreversed, and
+// the last one of them has only the applied statements.
+func (p *Plan) compensations(d dialect.Dialect, applied int) (compensations, unreversed []*Change) {
+	var changes []*Change
+	var last int
+	for _, c := range p.Changes {
+		if applied <= 0 {
+			break
+		}
+		changes = append(changes, c)
+		last = applied
+		applied -= len(c.SQLs)
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		n := len(changes[i].SQLs)
+		if i == len(changes)-1 {
+			n = last
+		}
+		reversed, err := changes[i].reverseApplied(d, n)
+		if err != nil {
+			c := *changes[i]
+			c.SQLs = c.SQLs[:n]
+			unreversed = append(unreversed, &c)
+			continue
+		}
+		compensations = append(compensations, reversed...)
+	}
+	return compensations, unreversed
+}
+
+// reverseApplied returns the changes to revert the first n statements of the change.
+func (c *Change) reverseApplied(d dialect.Dialect, n int) ([]*Change, error) {
+	if n >= len(c.SQLs) {
+		return c.reverse(d)
+	}
+	switch c.Kind {
+	case AddColumn:
+		// The column has been added by the first statement even if the rest,
+		// e.g. setting NOT NULL, has not been applied.
+		return c.reverse(d)
+	case ModifyColumn:
+		if c.oldField == nil || c.newField == nil {
+			break
+		}
+		// Find the definition of the column after the applied statements.
+		// It's verified by comparing the statements to make it with the applied ones.
+		withOldExtra, withNewExtra := *c.newField, *c.oldField
+		withOldExtra.Extra, withNewExtra.Extra = c.oldField.Extra, c.newField.Extra
+		for _, f := range []*field{&withOldExtra, &withNewExtra} {
+			if !reflect.DeepEqual(d.ModifyColumnSQL(c.oldField.ToField(), f.ToField()), c.SQLs[:n]) {
+				continue
+			}
+			partial := *c
+			partial.SQLs = c.SQLs[:n]
+			partial.newField = f
+			return partial.reverse(d)
+		}
+	}
+	return nil, fmt.Errorf("migu: cannot reverse the partially applied change: %s of %s", c.Kind, c.Table)
+}
+
+// reverse returns the changes to revert the change.
+func (c *Change) reverse(d dialect.Dialect) ([]*Change, error) {
+	if d == nil {
+		return nil, fmt.Errorf("migu: cannot reverse the change because the dialect is unknown: %s of %s", c.Kind, c.Table)
+	}
+	switch c.Kind {
+	case CreateTable:
+		return []*Change{{
+			Kind:     DropTable,
+			Table:    c.Table,
+			SQLs:     []string{fmt.Sprintf(`DROP TABLE %s`, dialect.QuoteTable(d, c.Table))},
+			oldTable: c.newTable,
+		}}, nil
+	case DropTable:
+		if c.oldTable == nil {
+			break
+		}
+		changes := []*Change{{
+			Kind:         CreateTable,
+			Table:        c.Table,
+			SQLs:         d.CreateTableSQL(c.oldTable.ToTable()),
+			Irreversible: true,
+			newTable:     c.oldTable,
+		}}
+		addIndexes, _ := makeIndexes(nil, c.oldTable.Fields)
+		for _, index := range addIndexes {
+			changes = append(changes, &Change{
+				Kind:  CreateIndex,
+				Table: c.Table,
+				Index: index.Name,
+				SQLs:  d.CreateIndexSQL(index.ToIndex()),
+				index: index,
+			})
+		}
+		return changes, nil
+	case ModifyTable:
+		td, ok := d.(dialect.TableModifier)
+		if !ok || c.oldTable == nil || c.newTable == nil {
+			break
+		}
+		return []*Change{{
+			Kind:  ModifyTable,
+			Table: c.Table,
+			SQLs:  td.ModifyTableSQL(c.newTable.ToTable(), c.oldTable.ToTable()),
+			// The data may have been converted by changing the character set or collation.
+			Irreversible: td.IsTableDataConverted(c.oldTable.ToTable(), c.newTable.ToTable()),
+			oldTable:     c.newTable,
+			newTable:     c.oldTable,
+		}}, nil
+	case AddColumn:
+		if c.newField == nil {
+			break
+		}
+		return []*Change{{
+			Kind:     DropColumn,
+			Table:    c.Table,
+			Column:   c.Column,
+			SQLs:     d.DropColumnSQL(c.newField.ToField()),
+			oldField: c.newField,
+		}}, nil
+	case DropColumn:
+		if c.oldField == nil {
+			break
+		}
+		changes := []*Change{{
+			Kind:         AddColumn,
+			Table:        c.Table,
+			Column:       c.Column,
+			SQLs:         d.AddColumnSQL(c.oldField.ToField()),
+			Irreversible: true,
+			newField:     c.oldField,
+		}}
+		for _, index := range c.indexes {
+			changes = append(changes, &Change{
+				Kind:  CreateIndex,
+				Table: c.Table,
+				Index: index.Name,
+				SQLs:  d.CreateIndexSQL(index.ToIndex()),
+				index: index,
+			})
+		}
+		return changes, nil
+	case ModifyColumn:
+		if c.oldField == nil || c.newField == nil {
+			break
+		}
+		return []*Change{{
+			Kind:   ModifyColumn,
+			Table:  c.Table,
+			Column: c.oldField.Column,
+			SQLs:   d.ModifyColumnSQL(c.newField.ToField(), c.oldField.ToField()),
+			// The data may have been converted or truncated by changing the type,
+			// NULL may have been replaced by setting NOT NULL, and the characters
+			// may have been converted by changing the character set or collation.
+			Irreversible: c.oldField.Type != c.newField.Type ||
+				(c.oldField.Nullable && !c.newField.Nullable) ||
+				c.oldField.Charset != c.newField.Charset ||
+				c.oldField.Collation != c.newField.Collation,
+			oldField: c.newField,
+			newField: c.oldField,
+		}}, nil
+	case ModifyPrimaryKey:
+		pd, ok := d.(dialect.PrimaryKeyModifier)
+		if !ok || (len(c.oldPks) == 0 && len(c.newPks) == 0) {
+			break
+		}
+		return []*Change{{
+			Kind:   ModifyPrimaryKey,
+			Table:  c.Table,
+			SQLs:   pd.ModifyPrimaryKeySQL(toFields(c.newPks), toFields(c.oldPks)),
+			oldPks: c.newPks,
+			newPks: c.oldPks,
+		}}, nil
+	case CreateIndex:
+		if c.index == nil {
+			break
+		}
+		return []*Change{{
+			Kind:  DropIndex,
+			Table: c.Table,
+			Index: c.Index,
+			SQLs:  d.DropIndexSQL(c.index.ToIndex()),
+			index: c.index,
+		}}, nil
+	case DropIndex:
+		if c.index == nil {
+			break
+		}
+		return []*Change{{
+			Kind:  CreateIndex,
+			Table: c.Table,
+			Index: c.Index,
+			SQLs:  d.CreateIndexSQL(c.index.ToIndex()),
+			index: c.index,
+		}}, nil
+	}
+	return nil, fmt.Errorf("migu: cannot reverse the change: %s of %s", c.Kind, c.Table)
+}
+
+// Apply applies the changes of the plan to the database in order.
+//
+// If fn is not nil, fn is called before each statement is executed, and the
+// function returned from fn is called with the result after it is executed.
+// If the statements are submitted in batches on commit (see
+// dialect.BatchTransactioner), fn is called for each statement after the
+// commit instead, so the time spent by each statement cannot be measured by fn;
+// it's spent by the whole batch. The statements after the failed one are not
+// passed to fn in either case.
+//
+// If the database doesn't support transactional DDL and some statements have
+// been applied before the failure, Apply returns *ApplyError.
+func Apply(ctx context.Context, d dialect.Dialect, plan *Plan, fn func(c *Change, sql string) func(err error)) error {
+	tx, err := d.BeginContext(ctx)
+	if err != nil {
+		return err
+	}
+	if t, ok := tx.(dialect.BatchTransactioner); ok && t.IsBatched() {
+		return plan.applyBatch(ctx, d, tx, fn)
+	}
+	var applied int
+	for _, c := range plan.Changes {
+		for _, sql := range c.SQLs {
+			var done func(error)
+			if fn != nil {
+				done = fn(c, sql)
+			}
+			err := tx.ExecContext(ctx, sql)
+			if done != nil {
+				done(err)
+			}
+			if err != nil {
+				tx.Rollback()
+				return plan.applyError(d, tx, applied, err)
+			}
+			applied++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		var berr *dialect.BatchError
+		if errors.As(err, &berr) {
+			applied = berr.Index
+		}
+		return plan.applyError(d, tx, applied, err)
+	}
+	return nil
+}
+
+// applyBatch applies the plan by the transaction that submits the statements in batches on commit.
+func (p *Plan) applyBatch(ctx context.Context, d dialect.Dialect, tx dialect.Transactioner, fn func(c *Change, sql string) func(err error)) error {
+	sqls := p.SQLs()
+	for _, sql := range sqls {
+		if err := tx.ExecContext(ctx, sql); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	applied := len(sqls)
+	err := tx.Commit()
+	if err != nil {
+		applied = 0
+		var berr *dialect.BatchError
+		if errors.As(err, &berr) {
+			applied = berr.Index
+		}
+	}
+	if fn != nil {
+		var i int
+	L:
+		for _, c := range p.Changes {
+			for _, sql := range c.SQLs {
+				if i > applied || (i == applied && err == nil) {
+					break L
+				}
+				var serr error
+				if i == applied {
+					serr = err
+				}
+				if done := fn(c, sql); done != nil {
+					done(serr)
+				}
+				i++
+			}
+		}
+	}
+	if err != nil {
+		return p.applyError(d, tx, applied, err)
+	}
+	return nil
+}
+
+func (p *Plan) applyError(d dialect.Dialect, tx dialect.Transactioner, applied int, err error) error {
+	if t, ok := tx.(dialect.DDLTransactioner); !ok || t.IsDDLTransactional() || applied == 0 {
+		return err
+	}
+	sqls := p.SQLs()
+	compensations, unreversed := p.compensations(d, applied)
+	return &ApplyError{
+		Applied:       sqls[:applied],
+		NotApplied:    sqls[applied:],
+		Compensations: compensations,
+		Unreversed:    unreversed,
+		Err:           err,
+	}
+}
+
+// ApplyError is returned when the plan has been partially applied to the
+// database because the database doesn't support transactional DDL.
+type ApplyError struct {
+	// Applied is the statements that have been applied to the database.
+	Applied []string
+
+	// NotApplied is the statements that have not been applied to the database.
+	// The first statement is the failed one.
+	NotApplied []string
+
+	// Compensations is the changes to revert the applied statements in order.
+	// The change that cannot restore the data, e.g. re-creating the dropped
+	// table, is marked as irreversible.
+	Compensations []*Change
+
+	// Unreversed is the applied changes that cannot be reverted by
+	// Compensations, e.g. because the plan has been read from the file.
+	// The partially applied change has only the applied statements.
+	Unreversed []*Change
+
+	// Err is the error that caused the failure.
+	Err error
+}
+
+func (e *ApplyError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "migu: schema has been partially migrated: %v\n", e.Err)
+	writeSQLs := func(sqls []string) {
+		for _, sql := range sqls {
+			fmt.Fprintf(&b, "  %s;\n", strings.Replace(sql, "\n", "\n  ", -1))
+		}
+	}
+	fmt.Fprintf(&b, "applied statements:\n")
+	writeSQLs(e.Applied)
+	fmt.Fprintf(&b, "not applied statements:\n")
+	writeSQLs(e.NotApplied)
+	if len(e.Unreversed) > 0 {
+		fmt.Fprintf(&b, "compensating statements (incomplete):\n")
+	} else {
+		fmt.Fprintf(&b, "compensating statements:\n")
+	}
+	for _, c := range e.Compensations {
+		if c.Irreversible {
+			fmt.Fprintf(&b, "  -- WARNING: %s of %s cannot restore the data.\n", c.Kind, c.Table)
+		}
+		writeSQLs(c.SQLs)
+	}
+	if len(e.Unreversed) > 0 {
+		fmt.Fprintf(&b, "changes that cannot be reverted:\n")
+		for _, c := range e.Unreversed {
+			fmt.Fprintf(&b, "  -- %s of %s\n", c.Kind, c.Table)
+			writeSQLs(c.SQLs)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,234 @@
+package migu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/naoina/go-stringutil"
+	"github.com/naoina/migu/dialect"
+)
+
+// Schema is the declarative schema written in YAML or JSON.
+// It can be used as the schema source instead of Go's structs. See Sync.
+type Schema struct {
+	Tables []*SchemaTable `json:"tables" yaml:"tables"`
+}
+
+// SchemaTable is the table in the schema.
+type SchemaTable struct {
+	Name    string          `json:"name" yaml:"name"`
+	Columns []*SchemaColumn `json:"columns" yaml:"columns"`
+
+	// PrimaryKey is the list of the column names of the primary key.
+	PrimaryKey []string `json:"primary_key,omitempty" yaml:"primary_key,omitempty"`
+
+	Indexes []*SchemaIndex `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+
+	// Option is the table option, e.g. ENGINE=InnoDB. It's the same as the table option annotation.
+	Option string `json:"option,omitempty" yaml:"option,omitempty"`
+
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// SchemaColumn is the column in the schema.
+// The column is NOT NULL unless Nullable is true.
+type SchemaColumn struct {
+	Name          string `json:"name" yaml:"name"`
+	Type          string `json:"type" yaml:"type"`
+	Nullable      bool   `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Default       string `json:"default,omitempty" yaml:"default,omitempty"`
+	AutoIncrement bool   `json:"auto_increment,omitempty" yaml:"auto_increment,omitempty"`
+	Extra         string `json:"extra,omitempty" yaml:"extra,omitempty"`
+	Comment       string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Charset       string `json:"charset,omitempty" yaml:"charset,omitempty"`
+	Collate       string `json:"collate,omitempty" yaml:"collate,omitempty"`
+}
+
+// SchemaIndex is the index in the schema.
+// If Name is empty, the name of the index is TABLE_COLUMN of the first column.
+// Columns must be in the same order as the columns of the table.
+type SchemaIndex struct {
+	Name    string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Columns []string `json:"columns" yaml:"columns"`
+	Unique  bool     `json:"unique,omitempty" yaml:"unique,omitempty"`
+}
+
+// isSchemaFile reports whether the file is the declarative schema file.
+func isSchemaFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// makeSchemaFileTableMap returns the tables which are defined by the declarative schema.
+// The filename and src parameters are the same as Sync.
+func makeSchemaFileTableMap(d dialect.Dialect, filename string, src interface{}) (map[string]*table, error) {
+	b, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	var s Schema
+	if strings.ToLower(filepath.Ext(filename)) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&s)
+	} else {
+		err = yaml.UnmarshalWithOptions(b, &s, yaml.DisallowUnknownField(), yaml.DisallowDuplicateKey())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("migu: %s: %w", filename, err)
+	}
+	tableMap := make(map[string]*table, len(s.Tables))
+	for _, t := range s.Tables {
+		tbl, err := t.toTable(d)
+		if err != nil {
+			return nil, fmt.Errorf("migu: %s: %w", filename, err)
+		}
+		if _, exists := tableMap[tbl.Name]; exists {
+			return nil, fmt.Errorf("migu: %s: table `%s' is already defined", filename, tbl.Name)
+		}
+		tableMap[tbl.Name] = tbl
+	}
+	return tableMap, nil
+}
+
+func (t *SchemaTable) toTable(d dialect.Dialect) (*table, error) {
+	if t.Name == "" {
+		return nil, fmt.Errorf("table name must be specified")
+	}
+	if len(t.Columns) == 0 {
+		return nil, fmt.Errorf("table `%s' must have at least one column", t.Name)
+	}
+	tbl := &table{
+		Name:    t.Name,
+		Option:  t.Option,
+		Comment: t.Comment,
+	}
+	fieldMap := make(map[string]*field, len(t.Columns))
+	positions := make(map[string]int, len(t.Columns))
+	for _, c := range t.Columns {
+		if c.Name == "" || c.Type == "" {
+			return nil, fmt.Errorf("column name and type of table `%s' must be specified", t.Name)
+		}
+		if _, exists := fieldMap[c.Name]; exists {
+			return nil, fmt.Errorf("column `%s' is already defined in table `%s'", c.Name, t.Name)
+		}
+		if _, ok := d.(dialect.CollationNormalizer); !ok && (c.Charset != "" || c.Collate != "") {
+			return nil, fmt.Errorf("charset and collate of column `%s' in table `%s' are not supported by the dialect", c.Name, t.Name)
+		}
+		f := &field{
+			Table:         t.Name,
+			Name:          stringutil.ToUpperCamelCase(c.Name),
+			Type:          d.ColumnType(normalizeColumnType(c.Type)),
+			Column:        c.Name,
+			Comment:       c.Comment,
+			AutoIncrement: c.AutoIncrement,
+			Default:       c.Default,
+			Extra:         c.Extra,
+			Nullable:      c.Nullable,
+			Charset:       c.Charset,
+			Collation:     c.Collate,
+		}
+		fieldMap[c.Name] = f
+		positions[c.Name] = len(tbl.Fields)
+		tbl.Fields = append(tbl.Fields, f)
+	}
+	lookup := func(name string) (*field, error) {
+		f, ok := fieldMap[name]
+		if !ok {
+			return nil, fmt.Errorf("column `%s' is not defined in table `%s'", name, t.Name)
+		}
+		return f, nil
+	}
+	for _, name := range t.PrimaryKey {
+		f, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		f.PrimaryKey = true
+	}
+	for _, index := range t.Indexes {
+		if len(index.Columns) == 0 {
+			return nil, fmt.Errorf("index of table `%s' must have at least one column", t.Name)
+		}
+		indexName := index.Name
+		if indexName == "" {
+			indexName = defaultIndexName(t.Name, index.Columns[0])
+		}
+		last := -1
+		for _, name := range index.Columns {
+			f, err := lookup(name)
+			if err != nil {
+				return nil, err
+			}
+			// The columns of the index are always in the order of the columns of the table.
+			if positions[name] < last {
+				return nil, fmt.Errorf("the columns of index `%s' must be in the same order as the columns of table `%s'", indexName, t.Name)
+			}
+			last = positions[name]
+			if index.Unique {
+				f.RawUniques = append(f.RawUniques, indexName)
+			} else {
+				f.RawIndexes = append(f.RawIndexes, indexName)
+			}
+		}
+	}
+	return tbl, nil
+}
+
+// DumpSchema returns the declarative schema of the database.
+// The schema can be written in YAML or JSON and be used as the schema source. See Sync.
+func DumpSchema(ctx context.Context, d dialect.Dialect) (*Schema, error) {
+	tableMap, err := makeDatabaseTableMap(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tableMap))
+	for name := range tableMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s := &Schema{}
+	for _, name := range names {
+		tbl := tableMap[name]
+		t := &SchemaTable{
+			Name:    name,
+			Option:  tbl.Option,
+			Comment: tbl.Comment,
+		}
+		for _, f := range tbl.Fields {
+			t.Columns = append(t.Columns, &SchemaColumn{
+				Name:          f.Column,
+				Type:          f.Type,
+				Nullable:      f.Nullable,
+				Default:       f.Default,
+				AutoIncrement: f.AutoIncrement,
+				Extra:         f.Extra,
+				Comment:       f.Comment,
+				Charset:       f.Charset,
+				Collate:       f.Collation,
+			})
+			if f.PrimaryKey {
+				t.PrimaryKey = append(t.PrimaryKey, f.Column)
+			}
+		}
+		indexes, _ := makeIndexes(nil, tbl.Fields)
+		for _, index := range indexes {
+			t.Indexes = append(t.Indexes, &SchemaIndex{
+				Name:    index.Name,
+				Columns: index.Columns,
+				Unique:  index.Unique,
+			})
+		}
+		s.Tables = append(s.Tables, t)
+	}
+	return s, nil
+}
@@ -0,0 +1,93 @@
+package migu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+// State represents the progress of applying a plan.
+// It's used to resume the synchronization after the failure.
+type State struct {
+	// Database is the name of the database that the plan is applied to.
+	Database string `json:"database"`
+
+	// SourceHash is the hash of the schema source that the plan was made from.
+	SourceHash string `json:"source_hash"`
+
+	// Applied is the number of statements of the plan that have been applied.
+	Applied int `json:"applied"`
+
+	Plan *Plan `json:"plan"`
+}
+
+// ReadStateFile reads the state from the file.
+func ReadStateFile(filename string) (*State, error) {
+	var s State
+	if err := readJSONFile(filename, &s); err != nil {
+		return nil, fmt.Errorf("migu: failed to read state file: %w", err)
+	}
+	if s.Plan == nil {
+		s.Plan = &Plan{}
+	}
+	return &s, nil
+}
+
+// WriteFile writes the state to the file.
+func (s *State) WriteFile(filename string) error {
+	return writeJSONFile(filename, s)
+}
+
+// Remaining returns the plan that consists of the statements that have not been applied.
+func (s *State) Remaining() *Plan {
+	return s.Plan.Skip(s.Applied)
+}
+
+// Skip returns a new plan that skips the first n statements of p.
+func (p *Plan) Skip(n int) *Plan {
+	plan := &Plan{d: p.d}
+	for _, c := range p.Changes {
+		if n >= len(c.SQLs) {
+			n -= len(c.SQLs)
+			continue
+		}
+		cc := *c
+		cc.SQLs = c.SQLs[n:]
+		n = 0
+		plan.add(&cc)
+	}
+	return plan
+}
+
+// SourceHash returns the hash of the schema source.
+// The filename and src parameters are the same as Sync, except that src must
+// be string or []byte.
+func SourceHash(filename string, src interface{}) (string, error) {
+	h := sha256.New()
+	switch s := src.(type) {
+	case nil:
+		filenames, err := collectFiles(filename)
+		if err != nil {
+			return "", err
+		}
+		sort.Strings(filenames)
+		for _, name := range filenames {
+			b, err := ioutil.ReadFile(name)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(h, "%s\x00%d\x00", filepath.Base(name), len(b))
+			h.Write(b)
+		}
+	case string:
+		h.Write([]byte(s))
+	case []byte:
+		h.Write(s)
+	default:
+		return "", fmt.Errorf("migu: unsupported type of source: %T", src)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -1,5 +1,10 @@
 package migu
 
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
 func inStrings(a []string, s string) bool {
 	for _, v := range a {
 		if v == s {
@@ -12,3 +17,19 @@ func inStrings(a []string, s string) bool {
 func isSpace(b byte) bool {
 	return b == ' ' || b == '\t'
 }
+
+func readJSONFile(filename string, v interface{}) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func writeJSONFile(filename string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(b, '\n'), 0644)
+}